@@ -0,0 +1,149 @@
+// Copyright 2025 Sam Caldwell
+//
+// Subcommands inspecting the mdlint configuration.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/asymmetric-effort/mdlint/internal/config"
+	"github.com/asymmetric-effort/mdlint/internal/engine"
+)
+
+// newConfigCmd returns the config command group. cfgPath and preset point at
+// the values of the persistent --config and --preset flags.
+func newConfigCmd(cfgPath, preset *string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the mdlint configuration",
+	}
+	cmd.AddCommand(newConfigPrintCmd(cfgPath, preset), newConfigPresetsCmd(), newConfigSchemaCmd(), newConfigMigrateCmd(cfgPath))
+	return cmd
+}
+
+// newConfigPresetsCmd returns the command listing the built-in presets.
+func newConfigPresetsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "presets",
+		Short: "List the built-in presets usable with extends or --preset",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, p := range config.Presets() {
+				fmt.Fprintf(cmd.OutOrStdout(), "%-10s %s\n", p.Name, p.Description)
+			}
+			return nil
+		},
+	}
+}
+
+// newConfigSchemaCmd returns the command printing the JSON Schema of
+// configuration files.
+func newConfigSchemaCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON Schema of configuration files for editor completion",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out, err := config.Schema()
+			if err != nil {
+				return err
+			}
+			_, err = cmd.OutOrStdout().Write(out)
+			return err
+		},
+	}
+}
+
+// newConfigMigrateCmd returns the command upgrading configuration files to
+// the current version.
+func newConfigMigrateCmd(cfgPath *string) *cobra.Command {
+	var dryRun bool
+	cmd := &cobra.Command{
+		Use:   "migrate [file...]",
+		Short: "Upgrade configuration files to the current version",
+		Long: "Rewrite configuration files of an older version, such as files without a " +
+			"version key, while keeping comments and key order. Without arguments, the file named by " +
+			"--config or the project configuration file of the working directory is migrated.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			paths := args
+			if len(paths) == 0 {
+				path := *cfgPath
+				if path == "" {
+					var err error
+					if path, err = config.FindProjectFile("."); err != nil {
+						return err
+					}
+					if path == "" {
+						return fmt.Errorf("no configuration file in the working directory")
+					}
+				}
+				paths = []string{path}
+			}
+			for _, path := range paths {
+				data, changes, err := config.Migrate(path)
+				if err != nil {
+					return err
+				}
+				if len(changes) == 0 {
+					fmt.Fprintf(cmd.ErrOrStderr(), "%s: already at version %d\n", path, config.CurrentVersion())
+					continue
+				}
+				for _, c := range changes {
+					fmt.Fprintf(cmd.ErrOrStderr(), "%s:%s\n", path, c)
+				}
+				if dryRun {
+					if _, err := cmd.OutOrStdout().Write(data); err != nil {
+						return err
+					}
+					continue
+				}
+				info, err := os.Stat(path)
+				if err != nil {
+					return err
+				}
+				if err := os.WriteFile(path, data, info.Mode().Perm()); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "print the migrated files instead of writing them")
+	return cmd
+}
+
+// newConfigPrintCmd returns the command printing the effective configuration,
+// each value annotated with the layer that set it.
+func newConfigPrintCmd(cfgPath, preset *string) *cobra.Command {
+	var format string
+	cmd := &cobra.Command{
+		Use:   "print [file]",
+		Short: "Print the effective configuration and where each value came from",
+		Long: "Print the configuration merged from defaults, the --preset preset, the user " +
+			"and project configuration files and MDLINT_ environment variables. Given a file, " +
+			"the paths overrides matching it and the mdlint key of its front matter are applied too.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig(*cfgPath, *preset, config.Config{})
+			if err != nil {
+				return err
+			}
+			if len(args) == 1 {
+				if cfg, err = engine.ConfigFor(args[0], cfg); err != nil {
+					return err
+				}
+			}
+			out, err := cfg.Marshal(format)
+			if err != nil {
+				return err
+			}
+			_, err = cmd.OutOrStdout().Write(out)
+			return err
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", "yaml", "output format: yaml or json")
+	return cmd
+}
@@ -8,74 +8,133 @@ import (
 	"io"
 	"log"
 	"os"
-	"github.com/sam-caldwell/mdlint/internal/version"
+	"strings"
+
+	"github.com/spf13/cobra"
+
 	"github.com/asymmetric-effort/mdlint/internal/config"
 	"github.com/asymmetric-effort/mdlint/internal/engine"
+	"github.com/asymmetric-effort/mdlint/internal/findings"
+	"github.com/asymmetric-effort/mdlint/internal/fix"
+	formatpkg "github.com/asymmetric-effort/mdlint/internal/format"
 	"github.com/asymmetric-effort/mdlint/internal/formatter"
-	"github.com/spf13/cobra"
+	_ "github.com/asymmetric-effort/mdlint/internal/rules"
+	"github.com/asymmetric-effort/mdlint/internal/version"
 )
 
-const version = "0.1.0"
-
 func main() {
 	var (
 		cfgPath     string
+		preset      string
 		quiet       bool
 		formatFlag  string
 		listRules   bool
 		showVersion bool
+		fixFlag     bool
+		dryRun      bool
+		patchFile   string
 	)
 	exitCode := 0
 	rootCmd := &cobra.Command{
 		Use:          "mdlint [files...]",
 		Short:        "mdlint lints Markdown files",
 		SilenceUsage: true,
+		Args:         cobra.ArbitraryArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if quiet {
 				log.SetOutput(io.Discard)
 			}
 			if showVersion {
-				fmt.Fprintf(cmd.OutOrStdout(), "mdlint %s\n", version)
+				fmt.Fprintf(cmd.OutOrStdout(), "mdlint %s\n", version.Version)
 				return nil
 			}
 			if listRules {
-				fmt.Fprintln(cmd.OutOrStdout(), "MD9000 TODO found")
+				for _, r := range engine.Rules() {
+					fmt.Fprintf(cmd.OutOrStdout(), "%s %s (%s)\n", r.ID(), r.Name(), r.DefaultSeverity())
+				}
 				return nil
 			}
-			cfg, err := config.Load(cfgPath)
+			cli := config.Config{Output: config.OutputConfig{Format: formatFlag}}
+			cfg, err := loadConfig(cfgPath, preset, cli)
 			if err != nil {
 				return err
 			}
-			if formatFlag != "" {
-				cfg.Format = formatFlag
+			eng := engine.Engine{Config: cfg}
+			// Files with invalid front matter are reported after the
+			// findings of the others.
+			var skipped error
+			if dryRun {
+				res, err := eng.PlanFixes(args)
+				if err != nil && !engine.IsFileError(err) {
+					return err
+				}
+				skipped = err
+				if err := writePatch(cmd.OutOrStdout(), patchFile, res); err != nil {
+					return err
+				}
+				if !quiet {
+					fmt.Fprintf(cmd.ErrOrStderr(), "%d fix(es) pending in %d file(s)\n", len(res.Fixed), len(res.Changes))
+				}
+				if len(res.Changes) > 0 {
+					exitCode = 1
+				}
+				return skipped
 			}
-			eng := engine.Engine{}
-			fs, err := eng.Run(args)
-			if err != nil {
-				return err
+			var fs []findings.Finding
+			if fixFlag {
+				res, err := eng.Fix(args)
+				if err != nil && !engine.IsFileError(err) {
+					return err
+				}
+				skipped = err
+				if !quiet {
+					reportFixes(cmd.ErrOrStderr(), res)
+				}
+				fs = res.Remaining
+			} else {
+				fs, err = eng.Run(args)
+				if err != nil && !engine.IsFileError(err) {
+					return err
+				}
+				skipped = err
 			}
 			if len(fs) == 0 {
-				return nil
+				return skipped
 			}
-			out, err := formatter.Format(fs, cfg.Format)
+			out, err := formatter.Format(fs, cfg.Output.Format)
 			if err != nil {
 				return err
 			}
 			if out != "" {
 				fmt.Fprint(cmd.OutOrStdout(), out)
 			}
-			if len(fs) > 0 {
-				exitCode = 1
+			threshold := findings.Severity(cfg.FailureThreshold)
+			for _, f := range fs {
+				if f.Severity.AtLeast(threshold) {
+					exitCode = 1
+					break
+				}
 			}
-			return nil
+			return skipped
 		},
 	}
-	rootCmd.Flags().StringVar(&cfgPath, "config", "", "config file")
+	rootCmd.PersistentFlags().StringVarP(&cfgPath, "config", "c", "", "config file (YAML, JSON or TOML)")
+	rootCmd.PersistentFlags().StringVar(&preset, "preset", "", "built-in preset applied beneath the configuration files")
 	rootCmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "suppress logs")
 	rootCmd.Flags().StringVar(&formatFlag, "format", "", "output format")
 	rootCmd.Flags().BoolVar(&listRules, "list-rules", false, "list supported rules")
 	rootCmd.Flags().BoolVar(&showVersion, "version", false, "print version")
+	rootCmd.Flags().BoolVar(&fixFlag, "fix", false, "apply fixes from fixable rules in place")
+	rootCmd.Flags().BoolVar(&dryRun, "fix-dry-run", false, "print pending fixes as a unified diff without writing files")
+	rootCmd.Flags().StringVar(&patchFile, "patch-file", "", "with --fix-dry-run, write the diff to this file for git apply")
+	rootCmd.MarkFlagsMutuallyExclusive("fix", "fix-dry-run")
 	rootCmd.SilenceErrors = true
+	rootCmd.AddCommand(newConfigCmd(&cfgPath, &preset))
+	config.Warn = func(msg string) {
+		if !quiet {
+			fmt.Fprintln(os.Stderr, "warning: "+msg)
+		}
+	}
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
@@ -83,3 +142,39 @@ func main() {
 	}
 	os.Exit(exitCode)
 }
+
+// loadConfig resolves the configuration from the file named by --config, or
+// from the project configuration of the working directory when it is empty.
+// A non-empty preset is layered beneath the configuration files.
+func loadConfig(cfgPath, preset string, cli config.Config) (config.Config, error) {
+	if preset != "" {
+		cli.Extends = []string{preset}
+	}
+	if cfgPath != "" {
+		return config.LoadFile(cli, cfgPath)
+	}
+	return config.Load(cli, ".")
+}
+
+// reportFixes summarizes an autofix run, listing every fixed finding followed
+// by the counts of fixed and remaining findings.
+func reportFixes(w io.Writer, res engine.FixResult) {
+	for _, f := range res.Fixed {
+		fmt.Fprintf(w, "fixed %s %s %s\n", formatpkg.Location(f), f.Rule, f.Message)
+	}
+	fmt.Fprintf(w, "%d fixed in %d file(s), %d remaining\n", len(res.Fixed), len(res.Changes), len(res.Remaining))
+}
+
+// writePatch renders the changes of res as a single unified diff, written to
+// path when given and to w otherwise.
+func writePatch(w io.Writer, path string, res engine.FixResult) error {
+	var sb strings.Builder
+	for _, c := range res.Changes {
+		sb.WriteString(fix.Diff(c.Path, c.Before, c.After))
+	}
+	if path != "" {
+		return os.WriteFile(path, []byte(sb.String()), 0o644)
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
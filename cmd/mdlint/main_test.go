@@ -8,7 +8,7 @@ import (
 	"os/exec"
 	"testing"
 
-	"github.com/sam-caldwell/mdlint/internal/version"
+	"github.com/asymmetric-effort/mdlint/internal/version"
 )
 
 // TestVersionFlag verifies that the --version flag prints the semantic version.
@@ -20,7 +20,7 @@ func TestVersionFlag(t *testing.T) {
 	if err := cmd.Run(); err != nil {
 		t.Fatalf("command failed: %v output: %s", err, out.String())
 	}
-	expected := version.Version + "\n"
+	expected := "mdlint " + version.Version + "\n"
 	if out.String() != expected {
 		t.Fatalf("expected %q got %q", expected, out.String())
 	}
@@ -1,17 +1,20 @@
-module github.com/sam-caldwell/mdlint
+module github.com/asymmetric-effort/mdlint
 
 go 1.24.6
 
 require (
+	github.com/alecthomas/chroma/v2 v2.13.0
+	github.com/pelletier/go-toml/v2 v2.2.4 // pinned: internal/config uses its unstable parser API
 	github.com/spf13/cobra v1.8.0
 	github.com/yuin/goldmark v1.7.13
 	github.com/yuin/goldmark-meta v1.1.0
+	golang.org/x/sync v0.16.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
-	github.com/alecthomas/chroma/v2 v2.13.0 // indirect
 	github.com/dlclark/regexp2 v1.11.0 // indirect
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
+	gopkg.in/yaml.v2 v2.3.0 // indirect
 )
@@ -5,174 +5,372 @@
 package config
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
 	"gopkg.in/yaml.v3"
+
+	"github.com/asymmetric-effort/mdlint/internal/glob"
 )
 
 // Severity represents a rule severity level.
 type Severity string
 
-// Config holds the top-level configuration for mdlint.
+// Config holds the top-level configuration for mdlint. The desc and enum
+// tags of the fields of Config and the types it holds describe them in Schema,
+// and fields tagged env:"-" cannot be set by environment variables.
 type Config struct {
-	Version          int                   `yaml:"version"`
-	Ignored          []string              `yaml:"ignored"`
-	Severity         map[string]Severity   `yaml:"severity"`
-	Paths            map[string]PathConfig `yaml:"paths"`
-	Spell            SpellConfig           `yaml:"spell"`
-	Heading          HeadingConfig         `yaml:"heading"`
-	Output           OutputConfig          `yaml:"output"`
-	FailureThreshold Severity              `yaml:"failure_threshold"`
+	Version          int                    `yaml:"version" env:"-" desc:"Configuration format version."`
+	IsRoot           *bool                  `yaml:"root" env:"-" desc:"Stop looking for configuration files in parent directories."`
+	Extends          []string               `yaml:"extends" env:"-" desc:"Configuration files, relative to this one, or built-in presets merged beneath this file in order."`
+	Ignored          []string               `yaml:"ignored" desc:"IDs of the rules that are not run."`
+	Severity         map[string]Severity    `yaml:"severity" desc:"Severity of findings by rule ID."`
+	Paths            map[string]PathConfig  `yaml:"paths" desc:"Overrides for the files matching each gitignore-style glob, relative to the configuration file."`
+	Rules            map[string]RuleOptions `yaml:"rules" desc:"Typed options by rule ID."`
+	Spell            SpellConfig            `yaml:"spell" desc:"Spell checking options."`
+	Heading          HeadingConfig          `yaml:"heading" desc:"Heading style options."`
+	Output           OutputConfig           `yaml:"output" desc:"Findings output options."`
+	Suppressions     SuppressionConfig      `yaml:"suppressions" desc:"Inline suppression comment options."`
+	FailureThreshold Severity               `yaml:"failure_threshold" desc:"Minimum severity that causes a non-zero exit."`
+
+	// Root is the directory the Paths patterns are relative to: the
+	// directory of the project configuration file. It is set by Load and
+	// LoadFile and is not read from configuration files.
+	Root string `yaml:"-"`
+	// Sources maps the dotted path of every value set by a configuration
+	// layer to the layer it came from, such as "default" or the path of a
+	// configuration file. Later layers replace the sources of the values they
+	// override. It is filled by Load and LoadFile and kept up to date by
+	// ForFile and WithFile; a nil map tracks nothing.
+	Sources map[string]string `yaml:"-"`
+
+	// tree discovers the configuration files of other directories when the
+	// configuration was loaded with Load.
+	tree *tree
 }
 
 // PathConfig defines per-path overrides.
 type PathConfig struct {
-	Ignored  []string            `yaml:"ignored"`
-	Severity map[string]Severity `yaml:"severity"`
+	Ignored  []string               `yaml:"ignored" desc:"IDs of additional rules that are not run."`
+	Severity map[string]Severity    `yaml:"severity" desc:"Severity of findings by rule ID."`
+	Rules    map[string]RuleOptions `yaml:"rules" desc:"Typed options by rule ID."`
 }
 
 // SpellConfig defines options for the spelling rule MD1000.
 type SpellConfig struct {
-	Lang        string   `yaml:"lang"`
-	AddWords    []string `yaml:"add_words"`
-	RejectWords []string `yaml:"reject_words"`
-	Filters     []string `yaml:"filters"`
+	Lang        string   `yaml:"lang" desc:"Dictionary language, such as en_US."`
+	AddWords    []string `yaml:"add_words" desc:"Words accepted in addition to the dictionary."`
+	RejectWords []string `yaml:"reject_words" desc:"Words reported even when the dictionary accepts them."`
+	Filters     []string `yaml:"filters" desc:"Filters applied to text before it is spell checked."`
 }
 
 // HeadingConfig defines options for heading style checks.
 type HeadingConfig struct {
-	Style      string `yaml:"style"`
-	AllowMixed *bool  `yaml:"allow_mixed"`
+	Style      string `yaml:"style" enum:"atx,setext,consistent" desc:"Required heading style; consistent requires every heading to match the first one."`
+	AllowMixed *bool  `yaml:"allow_mixed" desc:"Accept closed ATX headings such as '# Title #'."`
 }
 
 // OutputConfig defines formatting options for findings output.
 type OutputConfig struct {
-	Format string `yaml:"format"`
-	Color  string `yaml:"color"`
+	Format string `yaml:"format" enum:"json,text" desc:"Format of reported findings."`
+	Color  string `yaml:"color" enum:"auto,always,never" desc:"When to color text output."`
+}
+
+// SuppressionConfig defines how inline suppression comments are checked.
+type SuppressionConfig struct {
+	// ReportUnused reports directives naming unknown rules or silencing no
+	// finding.
+	ReportUnused *bool `yaml:"report_unused" desc:"Report directives naming unknown rules or silencing no finding."`
 }
 
 // DefaultConfig returns configuration with built-in defaults.
 func DefaultConfig() Config {
 	allowMixed := false
 	return Config{
-		Version:          1,
+		Version:          CurrentVersion(),
 		Output:           OutputConfig{Format: "json", Color: "auto"},
 		Heading:          HeadingConfig{AllowMixed: &allowMixed},
 		FailureThreshold: "warning",
 	}
 }
 
-// Load resolves configuration from user, project and CLI sources in precedence order.
-// CLI overrides are provided via the cli parameter; projectDir determines where the
-// project configuration file is looked up.
+// Load resolves configuration from user, project, environment and CLI
+// sources in precedence order. CLI overrides are provided via the cli
+// parameter; projectDir determines where the project configuration files are
+// looked up. Every ProjectFile found between projectDir and its repository
+// root is merged, outermost first, and the returned configuration discovers
+// the files of other directories when resolving the configuration of a linted
+// file with Resolve. An empty projectDir skips project configuration. Presets
+// and files listed in cli.Extends, such as the one chosen with --preset, are
+// merged right above the defaults so that configuration files still override
+// them. The MDLINT_ environment variables described by EnvPrefix are merged
+// between the project configuration and cli.
 func Load(cli Config, projectDir string) (Config, error) {
-	cfg := DefaultConfig()
-
-	if userCfg, err := readConfigFile(userConfigPath()); err == nil {
-		merge(&cfg, userCfg)
-	} else if !errors.Is(err, os.ErrNotExist) {
+	cfg, err := baseConfig(cli.Extends)
+	if err != nil {
 		return Config{}, err
 	}
-
+	env, err := envLayers(os.Environ())
+	if err != nil {
+		return Config{}, fmt.Errorf("environment: %w", err)
+	}
 	if projectDir != "" {
-		projPath := filepath.Join(projectDir, ".mdlintrc.yaml")
-		if projCfg, err := readConfigFile(projPath); err == nil {
-			merge(&cfg, projCfg)
-		} else if !errors.Is(err, os.ErrNotExist) {
+		if cfg, err = newTree(cfg, env, cli).resolve(projectDir); err != nil {
 			return Config{}, err
 		}
+	} else {
+		cfg.mergeLayers(env)
+		merge(&cfg, cli)
+		cfg.record(cli, SourceCLI)
 	}
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
 
+// LoadFile resolves configuration like Load but reads the project layer from
+// the explicitly named file, which must exist. No other project files are
+// discovered.
+func LoadFile(cli Config, path string) (Config, error) {
+	cfg, err := baseConfig(cli.Extends)
+	if err != nil {
+		return Config{}, err
+	}
+	layers, err := readLayers(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("project configuration: %w", err)
+	}
+	env, err := envLayers(os.Environ())
+	if err != nil {
+		return Config{}, fmt.Errorf("environment: %w", err)
+	}
+	cfg.mergeLayers(layers)
+	cfg.mergeLayers(env)
 	merge(&cfg, cli)
-
+	cfg.record(cli, SourceCLI)
+	cfg.Root = filepath.Dir(path)
 	if err := cfg.Validate(); err != nil {
 		return Config{}, err
 	}
+	return cfg, nil
+}
+
+// baseConfig returns the defaults with the configurations named by extends
+// and then the user configuration merged on top.
+func baseConfig(extends []string) (Config, error) {
+	cfg := DefaultConfig()
+	cfg.Sources = make(map[string]string)
+	cfg.record(cfg, SourceDefault)
+
+	if len(extends) > 0 {
+		layers, err := extend(layer{Config{Extends: extends}, SourceCLI}, SourceCLI, ".", nil)
+		if err != nil {
+			return Config{}, err
+		}
+		cfg.mergeLayers(layers[:len(layers)-1])
+	}
 
+	userPath := userConfigPath()
+	if layers, err := readLayers(userPath); err == nil {
+		cfg.mergeLayers(layers)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return Config{}, fmt.Errorf("user configuration: %w", err)
+	}
 	return cfg, nil
 }
 
+// readConfigFile reads and validates the configuration file at path. Files
+// ending in .toml are TOML; all others are YAML, which includes JSON. Files
+// of an older version are upgraded in memory, reporting the deprecated
+// settings through Warn. Errors in the file are *Error values naming path
+// and, when known, the line and column of the offending key.
 func readConfigFile(path string) (Config, error) {
-	data, err := os.ReadFile(path)
+	_, doc, err := readNode(path)
 	if err != nil {
 		return Config{}, err
 	}
-	cfg, err := parseYAML(data)
+	warnDeprecated(path, upgrade(doc))
+	cfg, err := decodeConfig(doc)
+	if err == nil {
+		err = cfg.Validate()
+	}
 	if err != nil {
-		return Config{}, err
+		return Config{}, locate(err, path, doc)
 	}
-	if err := cfg.Validate(); err != nil {
+	return cfg, nil
+}
+
+// readNode reads the configuration file at path into its node tree, returning
+// the contents of the file too.
+func readNode(path string) ([]byte, *yaml.Node, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	var doc *yaml.Node
+	if filepath.Ext(path) == ".toml" {
+		doc, err = parseTOML(data)
+	} else {
+		doc, err = parseNode(data)
+	}
+	if err != nil {
+		return nil, nil, locate(err, path, nil)
+	}
+	return data, doc, nil
+}
+
+// parseNode parses a YAML document into its node tree.
+func parseNode(data []byte) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, yamlError(err)
+	}
+	return &doc, nil
+}
+
+// decodeConfig decodes the configuration document n, rejecting unknown keys.
+func decodeConfig(n *yaml.Node) (Config, error) {
+	var cfg Config
+	if n.Kind == yaml.DocumentNode && len(n.Content) == 0 {
+		return cfg, nil
+	}
+	if err := checkKeys(n, reflect.TypeOf(cfg), nil); err != nil {
 		return Config{}, err
 	}
+	if err := n.Decode(&cfg); err != nil {
+		return Config{}, yamlError(err)
+	}
 	return cfg, nil
 }
 
+// parseYAML decodes a YAML configuration, rejecting unknown keys.
 func parseYAML(data []byte) (Config, error) {
-	var cfg Config
-	dec := yaml.NewDecoder(bytes.NewReader(data))
-	dec.KnownFields(true)
-	if err := dec.Decode(&cfg); err != nil {
+	doc, err := parseNode(data)
+	if err != nil {
 		return Config{}, err
 	}
-	return cfg, nil
+	return decodeConfig(doc)
 }
 
-// Validate performs custom schema checks beyond YAML decoding.
+// Validate performs custom schema checks beyond YAML decoding. Errors are
+// *Error values naming the offending key, which readers of configuration
+// files locate in the file.
 func (c Config) Validate() error {
-	if c.Version != 1 {
-		return fmt.Errorf("unsupported version %d", c.Version)
+	if c.Version != CurrentVersion() {
+		return keyErrorf([]string{"version"}, "unsupported version %d", c.Version)
 	}
 
-	validSev := map[Severity]bool{"suggestion": true, "warning": true, "error": true}
-	checkSev := func(sev Severity) error {
-		if sev == "" {
-			return nil
+	if err := checkRuleIDs([]string{"ignored"}, "ignored", c.Ignored); err != nil {
+		return err
+	}
+	if err := checkRuleIDs([]string{"severity"}, "severity", sortedKeys(c.Severity)); err != nil {
+		return err
+	}
+	if err := checkSeverities(c.Severity); err != nil {
+		return within(err, "", "severity")
+	}
+	for _, p := range sortedKeys(c.Paths) {
+		pc := c.Paths[p]
+		key := []string{"paths", p}
+		if strings.HasPrefix(p, "!") {
+			return keyErrorf(key, "paths: negated pattern %q is not supported", p)
 		}
-		if !validSev[sev] {
-			return fmt.Errorf("invalid severity %q", sev)
+		if err := glob.Validate(p); err != nil {
+			return keyErrorf(key, "paths: %w", err)
 		}
-		return nil
-	}
-
-	for _, sev := range c.Severity {
-		if err := checkSev(sev); err != nil {
+		if err := checkRuleIDs(append(key, "ignored"), fmt.Sprintf("paths %q: ignored", p), pc.Ignored); err != nil {
+			return err
+		}
+		if err := checkRuleIDs(append(key, "severity"), fmt.Sprintf("paths %q: severity", p), sortedKeys(pc.Severity)); err != nil {
 			return err
 		}
+		if err := checkSeverities(pc.Severity); err != nil {
+			return within(err, "", "paths", p, "severity")
+		}
+		if err := validateRules(pc.Rules); err != nil {
+			return within(err, fmt.Sprintf("paths %q: ", p), "paths", p)
+		}
 	}
-	for _, pc := range c.Paths {
-		for _, sev := range pc.Severity {
-			if err := checkSev(sev); err != nil {
-				return err
-			}
+	if err := validateRules(c.Rules); err != nil {
+		return err
+	}
+
+	if err := checkEnum([]string{"heading", "style"}, "heading style", c.Heading.Style, tagEnum(HeadingConfig{}, "style")); err != nil {
+		return err
+	}
+	if err := checkEnum([]string{"output", "format"}, "output format", c.Output.Format, tagEnum(OutputConfig{}, "format")); err != nil {
+		return err
+	}
+	if err := checkEnum([]string{"output", "color"}, "output color", c.Output.Color, tagEnum(OutputConfig{}, "color")); err != nil {
+		return err
+	}
+	if err := checkEnum([]string{"failure_threshold"}, "failure threshold: invalid severity", string(c.FailureThreshold), severities); err != nil {
+		return err
+	}
+	return nil
+}
+
+// checkRuleIDs reports the first of ids naming no known rule, suggesting the
+// closest known ID. IDs of a mapping are located at their own key below key.
+func checkRuleIDs(key []string, what string, ids []string) error {
+	known := knownRuleIDs()
+	for _, id := range ids {
+		if contains(known, id) {
+			continue
+		}
+		at := key
+		if key[len(key)-1] == "severity" {
+			at = append(key[:len(key):len(key)], id)
 		}
+		return keyErrorf(at, "%s: unknown rule %q%s", what, id, didYouMean(id, known))
 	}
+	return nil
+}
 
-	if c.Heading.Style != "" {
-		switch c.Heading.Style {
-		case "atx", "setext", "consistent":
-		default:
-			return fmt.Errorf("invalid heading style %q", c.Heading.Style)
+// checkSeverities validates the severities of a severity section, in rule
+// order.
+func checkSeverities(m map[string]Severity) error {
+	for _, id := range sortedKeys(m) {
+		if err := checkEnum([]string{id}, "severity", string(m[id]), severities); err != nil {
+			return err
 		}
 	}
+	return nil
+}
 
-	switch c.Output.Format {
-	case "", "json", "text":
-	default:
-		return fmt.Errorf("invalid output format %q", c.Output.Format)
+// checkEnum reports a value of key that is neither empty nor one of values,
+// suggesting the closest valid value.
+func checkEnum(key []string, what, value string, values []string) error {
+	if value == "" || contains(values, value) {
+		return nil
 	}
-	switch c.Output.Color {
-	case "", "auto", "always", "never":
-	default:
-		return fmt.Errorf("invalid output color %q", c.Output.Color)
+	return keyErrorf(key, "invalid %s %q%s", what, value, didYouMean(value, values))
+}
+
+// tagEnum returns the values listed by the enum tag of the field of the
+// struct v named name by its yaml tag.
+func tagEnum(v any, name string) []string {
+	f, ok := fieldByName(reflect.TypeOf(v), name)
+	if !ok || f.Tag.Get("enum") == "" {
+		return nil
 	}
+	return strings.Split(f.Tag.Get("enum"), ",")
+}
 
-	if err := checkSev(c.FailureThreshold); err != nil {
-		return fmt.Errorf("invalid failure threshold: %w", err)
+// sortedKeys returns the keys of m in order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
 	}
-	return nil
+	sort.Strings(keys)
+	return keys
 }
 
 func merge(dst *Config, src Config) {
@@ -207,9 +405,11 @@ func merge(dst *Config, src Config) {
 					existing.Severity[rk] = rv
 				}
 			}
+			existing.Rules = mergeRules(existing.Rules, pc.Rules)
 			dst.Paths[p] = existing
 		}
 	}
+	dst.Rules = mergeRules(dst.Rules, src.Rules)
 	if src.Spell.Lang != "" {
 		dst.Spell.Lang = src.Spell.Lang
 	}
@@ -234,6 +434,9 @@ func merge(dst *Config, src Config) {
 	if src.Output.Color != "" {
 		dst.Output.Color = src.Output.Color
 	}
+	if src.Suppressions.ReportUnused != nil {
+		dst.Suppressions.ReportUnused = src.Suppressions.ReportUnused
+	}
 	if src.FailureThreshold != "" {
 		dst.FailureThreshold = src.FailureThreshold
 	}
@@ -250,3 +453,17 @@ func userConfigPath() string {
 	return filepath.Join(home, ".config", "mdlint", "config.yaml")
 }
 
+// mergeRules appends the option layers of src to those of dst, returning dst
+// or a new map when dst is nil. Layer slices are copied, never shared.
+func mergeRules(dst, src map[string]RuleOptions) map[string]RuleOptions {
+	if len(src) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = make(map[string]RuleOptions, len(src))
+	}
+	for id, layers := range src {
+		dst[id] = append(append(RuleOptions(nil), dst[id]...), layers...)
+	}
+	return dst
+}
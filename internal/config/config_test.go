@@ -87,4 +87,3 @@ func TestLoadValidationError(t *testing.T) {
 		t.Fatalf("expected error for unknown field")
 	}
 }
-
@@ -0,0 +1,172 @@
+// Copyright 2024 The mdlint Authors
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ProjectFile is the preferred name of the configuration files discovered in
+// the directories of linted files.
+const ProjectFile = ".mdlintrc.yaml"
+
+// ProjectFiles lists every name a discovered configuration file may have. A
+// directory may hold at most one of them.
+var ProjectFiles = []string{
+	".mdlintrc.yaml", ".mdlintrc.yml", ".mdlintrc.json", ".mdlintrc.toml",
+	".mdlint.yaml", ".mdlint.yml", ".mdlint.json", ".mdlint.toml",
+}
+
+// FindProjectFile returns the path of the configuration file in dir, named as
+// one of ProjectFiles, or "" when there is none. It reports an error when
+// several candidates exist, since silently preferring one would hide the
+// settings of the others.
+func FindProjectFile(dir string) (string, error) {
+	var found []string
+	for _, name := range ProjectFiles {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err == nil && !info.IsDir() {
+			found = append(found, name)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return "", nil
+	case 1:
+		return filepath.Join(dir, found[0]), nil
+	}
+	return "", fmt.Errorf("conflicting configuration files in %s: %s; keep only one", dir, strings.Join(found, ", "))
+}
+
+// tree discovers the project configuration files applying to each directory.
+// A directory's configuration is its parent's with the directory's own
+// configuration file, named as one of ProjectFiles, merged on top. Discovery
+// stops at a file marked root: true, at the repository root, holding a .git
+// entry, or at the file system root. Results are cached per directory so that
+// every file is read at most once.
+type tree struct {
+	base Config  // defaults and user configuration
+	env  []layer // environment variable overrides
+	cli  Config  // command-line overrides, merged last
+
+	mu   sync.Mutex
+	dirs map[string]dirConfig
+}
+
+// dirConfig is the cached discovery result of a directory.
+type dirConfig struct {
+	cfg Config
+	err error
+}
+
+// newTree returns a tree layering discovered files between base and the
+// environment and cli overrides.
+func newTree(base Config, env []layer, cli Config) *tree {
+	return &tree{base: base, env: env, cli: cli, dirs: make(map[string]dirConfig)}
+}
+
+// resolve returns the configuration of the directory dir with the
+// environment and command-line overrides applied.
+func (t *tree) resolve(dir string) (Config, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return Config{}, err
+	}
+	t.mu.Lock()
+	dc := t.dir(abs)
+	t.mu.Unlock()
+	if dc.err != nil {
+		return Config{}, dc.err
+	}
+	out := dc.cfg.clone()
+	out.mergeLayers(t.env)
+	merge(&out, t.cli)
+	out.record(t.cli, SourceCLI)
+	out.tree = t
+	return out, nil
+}
+
+// dir returns the discovered configuration of the absolute directory dir,
+// excluding environment and command-line overrides. t.mu must be held.
+func (t *tree) dir(dir string) dirConfig {
+	if dc, ok := t.dirs[dir]; ok {
+		return dc
+	}
+	dc := t.discover(dir)
+	t.dirs[dir] = dc
+	return dc
+}
+
+// discover computes the configuration of dir from its parent's.
+func (t *tree) discover(dir string) dirConfig {
+	path, err := FindProjectFile(dir)
+	if err != nil {
+		return dirConfig{err: err}
+	}
+	var layers []layer
+	if path != "" {
+		if layers, err = readLayers(path); err != nil {
+			return dirConfig{err: fmt.Errorf("project configuration: %w", err)}
+		}
+	}
+	found := len(layers) > 0
+
+	var out Config
+	parent := filepath.Dir(dir)
+	if (found && isTrue(layers[len(layers)-1].cfg.IsRoot)) || isRepoRoot(dir) || parent == dir {
+		out = t.base.clone()
+		out.Root = dir
+	} else {
+		dc := t.dir(parent)
+		if dc.err != nil {
+			return dc
+		}
+		out = dc.cfg.clone()
+	}
+	// Path patterns of extended files are relative to the extending file.
+	for i := range layers {
+		layers[i].cfg.Paths = rebase(layers[i].cfg.Paths, out.Root, dir)
+	}
+	out.mergeLayers(layers)
+	return dirConfig{cfg: out}
+}
+
+// isTrue reports whether an optional boolean is set to true.
+func isTrue(b *bool) bool {
+	return b != nil && *b
+}
+
+// isRepoRoot reports whether dir is the root of a repository.
+func isRepoRoot(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".git"))
+	return err == nil
+}
+
+// rebase rewrites path patterns relative to dir into patterns relative to
+// root, an ancestor of dir, so that the paths of nested configuration files
+// only apply below their own directory.
+func rebase(paths map[string]PathConfig, root, dir string) map[string]PathConfig {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || rel == "." || len(paths) == 0 {
+		return paths
+	}
+	prefix := filepath.ToSlash(rel)
+	out := make(map[string]PathConfig, len(paths))
+	for p, pc := range paths {
+		switch trimmed := strings.TrimSuffix(p, "/"); {
+		case strings.HasPrefix(p, "/"):
+			p = prefix + p
+		case !strings.Contains(trimmed, "/"):
+			p = prefix + "/**/" + p
+		default:
+			p = prefix + "/" + p
+		}
+		out[p] = pc
+	}
+	return out
+}
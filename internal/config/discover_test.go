@@ -0,0 +1,164 @@
+// Copyright 2025 Sam Caldwell
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeFile creates path with the given contents and any missing parents.
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestDiscovery verifies that nested project files extend their parents up
+// to the repository root.
+func TestDiscovery(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	repo := t.TempDir()
+	writeFile(t, filepath.Join(repo, ".git", "HEAD"), "")
+	writeFile(t, filepath.Join(repo, ProjectFile), "version: 1\nignored: [MD1500]\nseverity:\n  MD1000: error\n")
+	writeFile(t, filepath.Join(repo, "pkg", ProjectFile), "version: 1\nignored: [MD1400]\npaths:\n  \"*.md\":\n    severity:\n      MD1800: error\n")
+	writeFile(t, filepath.Join(repo, "pkg", "docs", "a.md"), "")
+	writeFile(t, filepath.Join(repo, "b.md"), "")
+
+	cfg, err := Load(Config{FailureThreshold: "error"}, repo)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !reflect.DeepEqual(cfg.Ignored, []string{"MD1500"}) {
+		t.Fatalf("unexpected ignored at the root: %v", cfg.Ignored)
+	}
+
+	got, err := cfg.Resolve(filepath.Join(repo, "pkg", "docs", "a.md"))
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if !reflect.DeepEqual(got.Ignored, []string{"MD1500", "MD1400"}) {
+		t.Fatalf("unexpected ignored: %v", got.Ignored)
+	}
+	if got.Severity["MD1000"] != "error" || got.Severity["MD1800"] != "error" || got.FailureThreshold != "error" {
+		t.Fatalf("unexpected configuration: %+v", got)
+	}
+	if src := got.Sources["ignored[MD1400]"]; src != filepath.Join(repo, "pkg", ProjectFile) {
+		t.Fatalf("unexpected source %q", src)
+	}
+
+	// The nested paths entry only applies below its own directory.
+	got, err = cfg.Resolve(filepath.Join(repo, "b.md"))
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if _, ok := got.Severity["MD1800"]; ok || !reflect.DeepEqual(got.Ignored, []string{"MD1500"}) {
+		t.Fatalf("nested configuration applied outside its directory: %+v", got)
+	}
+}
+
+// TestDiscoveryRoot verifies that root: true stops discovery and that
+// results are cached per directory.
+func TestDiscoveryRoot(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	repo := t.TempDir()
+	writeFile(t, filepath.Join(repo, ".git", "HEAD"), "")
+	writeFile(t, filepath.Join(repo, ProjectFile), "version: 1\nignored: [MD1500]\n")
+	nested := filepath.Join(repo, "vendor", ProjectFile)
+	writeFile(t, nested, "version: 1\nroot: true\nignored: [MD1000]\n")
+
+	cfg, err := Load(Config{}, repo)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	doc := filepath.Join(repo, "vendor", "x", "a.md")
+	got, err := cfg.Resolve(doc)
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if !reflect.DeepEqual(got.Ignored, []string{"MD1000"}) {
+		t.Fatalf("expected discovery to stop at the root marker: %v", got.Ignored)
+	}
+
+	writeFile(t, nested, "version: 1\nroot: true\nignored: [MD1800]\n")
+	got, err = cfg.Resolve(doc)
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if !reflect.DeepEqual(got.Ignored, []string{"MD1000"}) {
+		t.Fatalf("expected cached configuration: %v", got.Ignored)
+	}
+}
+
+// TestDiscoveryInvalid reports broken nested files with their path.
+func TestDiscoveryInvalid(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	repo := t.TempDir()
+	writeFile(t, filepath.Join(repo, ".git", "HEAD"), "")
+	bad := filepath.Join(repo, "sub", ProjectFile)
+	writeFile(t, bad, "version: 2\n")
+
+	cfg, err := Load(Config{}, repo)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if _, err := cfg.Resolve(filepath.Join(repo, "sub", "a.md")); err == nil || !strings.Contains(err.Error(), bad) {
+		t.Fatalf("expected error naming %s, got %v", bad, err)
+	}
+}
+
+// TestRebase verifies how nested path patterns are anchored.
+func TestRebase(t *testing.T) {
+	got := rebase(map[string]PathConfig{"*.md": {}, "docs/**": {}, "/README.md": {}, "gen/": {}}, "/repo", "/repo/pkg")
+	var keys []string
+	for k := range got {
+		keys = append(keys, k)
+	}
+	for _, want := range []string{"pkg/**/*.md", "pkg/docs/**", "pkg/README.md", "pkg/**/gen/"} {
+		if _, ok := got[want]; !ok {
+			t.Fatalf("missing %q in %v", want, keys)
+		}
+	}
+}
+
+// TestDiscoveryNames verifies that every supported file name is discovered
+// and that several candidates in one directory conflict.
+func TestDiscoveryNames(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	contents := map[string]string{
+		".yaml": "version: 1\nignored: [MD1000]\n",
+		".yml":  "version: 1\nignored: [MD1000]\n",
+		".json": `{"version": 1, "ignored": ["MD1000"]}`,
+		".toml": "version = 1\nignored = [\"MD1000\"]\n",
+	}
+	for _, name := range ProjectFiles {
+		repo := t.TempDir()
+		writeFile(t, filepath.Join(repo, ".git", "HEAD"), "")
+		writeFile(t, filepath.Join(repo, name), contents[filepath.Ext(name)])
+		cfg, err := Load(Config{}, repo)
+		if err != nil {
+			t.Fatalf("%s: Load: %v", name, err)
+		}
+		if !reflect.DeepEqual(cfg.Ignored, []string{"MD1000"}) {
+			t.Fatalf("%s: unexpected ignored: %v", name, cfg.Ignored)
+		}
+	}
+
+	repo := t.TempDir()
+	writeFile(t, filepath.Join(repo, ".git", "HEAD"), "")
+	writeFile(t, filepath.Join(repo, ".mdlint.yaml"), contents[".yaml"])
+	writeFile(t, filepath.Join(repo, ".mdlintrc.json"), contents[".json"])
+	_, err := Load(Config{}, repo)
+	if err == nil || !strings.Contains(err.Error(), "conflicting configuration files") ||
+		!strings.Contains(err.Error(), ".mdlintrc.json, .mdlint.yaml") {
+		t.Fatalf("expected conflict error, got %v", err)
+	}
+}
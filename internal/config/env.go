@@ -0,0 +1,136 @@
+// Copyright 2024 The mdlint Authors
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix starts the names of the environment variables overriding
+// configuration values. A value's variable is its dotted YAML path in upper
+// case with underscores, such as MDLINT_OUTPUT_FORMAT for output.format;
+// lists are comma separated, as in MDLINT_IGNORED=MD1000,MD1400, and
+// MDLINT_SEVERITY_<RULE> sets the severity of a rule.
+const EnvPrefix = "MDLINT_"
+
+// envSeverity starts the names of the variables setting rule severities.
+const envSeverity = EnvPrefix + "SEVERITY_"
+
+// envVar is an environment variable overriding a configuration value.
+type envVar struct {
+	name string
+	// key is the YAML path of the value.
+	key []string
+	// list is true for lists, which are comma separated.
+	list bool
+}
+
+// envVars returns the variables overriding the scalar and list values of
+// Config, excluding fields tagged env:"-". Maps such as severity have no
+// variable of their own.
+func envVars() []envVar {
+	var vars []envVar
+	var add func(t reflect.Type, key []string)
+	add = func(t reflect.Type, key []string) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+			if !f.IsExported() || name == "-" || f.Tag.Get("env") == "-" {
+				continue
+			}
+			k := append(key[:len(key):len(key)], name)
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			switch ft.Kind() {
+			case reflect.Struct:
+				add(ft, k)
+			case reflect.Map:
+			default:
+				vars = append(vars, envVar{
+					name: EnvPrefix + strings.ToUpper(strings.Join(k, "_")),
+					key:  k,
+					list: ft.Kind() == reflect.Slice,
+				})
+			}
+		}
+	}
+	add(reflect.TypeOf(Config{}), nil)
+	return vars
+}
+
+// envLayers returns a layer for every MDLINT_ variable set to a non-empty
+// value in environ, a list of "NAME=value" entries as returned by os.Environ,
+// in the order of their names. Unknown variables are reported through Warn.
+func envLayers(environ []string) ([]layer, error) {
+	vars := envVars()
+	names := make([]string, 0, len(vars))
+	byName := make(map[string]envVar, len(vars))
+	for _, v := range vars {
+		names = append(names, v.name)
+		byName[v.name] = v
+	}
+	env := append([]string(nil), environ...)
+	sort.Strings(env)
+
+	var layers []layer
+	for _, kv := range env {
+		name, value, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(name, EnvPrefix) || value == "" {
+			continue
+		}
+		v, ok := byName[name]
+		switch {
+		case ok:
+		case strings.HasPrefix(name, envSeverity) && len(name) > len(envSeverity):
+			v = envVar{name: name, key: []string{"severity", strings.TrimPrefix(name, envSeverity)}}
+		default:
+			if Warn != nil {
+				Warn(fmt.Sprintf("unknown environment variable %s%s", name, didYouMean(name, names)))
+			}
+			continue
+		}
+		cfg, err := envConfig(v, value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		layers = append(layers, layer{cfg, SourceEnv + " " + name})
+	}
+	return layers, nil
+}
+
+// envConfig decodes and validates the configuration setting the value of v.
+func envConfig(v envVar, value string) (Config, error) {
+	n := &yaml.Node{Kind: yaml.ScalarNode, Value: value}
+	if v.list {
+		n = &yaml.Node{Kind: yaml.SequenceNode}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				n.Content = append(n.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: item})
+			}
+		}
+	}
+	for i := len(v.key) - 1; i >= 0; i-- {
+		n = &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{
+			{Kind: yaml.ScalarNode, Tag: "!!str", Value: v.key[i]}, n,
+		}}
+	}
+	cfg, err := decodeConfig(n)
+	if err != nil {
+		return Config{}, err
+	}
+	// The variable sets a single value; the version is not among them.
+	cfg.Version = CurrentVersion()
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	cfg.Version = 0
+	return cfg, nil
+}
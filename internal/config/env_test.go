@@ -0,0 +1,90 @@
+// Copyright 2025 Sam Caldwell
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestEnvLayer verifies that MDLINT_ variables override the project
+// configuration, are overridden by command-line flags and are recorded as
+// their own sources.
+func TestEnvLayer(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".git", "HEAD"), "")
+	writeFile(t, filepath.Join(dir, ProjectFile), "version: 1\nignored: [MD1500]\nfailure_threshold: error\noutput:\n  format: json\n  color: never\n")
+	t.Setenv("MDLINT_FAILURE_THRESHOLD", "suggestion")
+	t.Setenv("MDLINT_OUTPUT_FORMAT", "text")
+	t.Setenv("MDLINT_OUTPUT_COLOR", "always")
+	t.Setenv("MDLINT_IGNORED", "MD1000, MD1400")
+	t.Setenv("MDLINT_SEVERITY_MD1800", "error")
+	t.Setenv("MDLINT_SUPPRESSIONS_REPORT_UNUSED", "true")
+	t.Setenv("MDLINT_SPELL_LANG", "")
+
+	cfg, err := Load(Config{Output: OutputConfig{Color: "auto"}}, dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.FailureThreshold != "suggestion" || cfg.Output.Format != "text" || cfg.Output.Color != "auto" ||
+		cfg.Severity["MD1800"] != "error" || cfg.Suppressions.ReportUnused == nil || !*cfg.Suppressions.ReportUnused {
+		t.Fatalf("unexpected configuration: %+v", cfg)
+	}
+	if !reflect.DeepEqual(cfg.Ignored, []string{"MD1500", "MD1000", "MD1400"}) {
+		t.Fatalf("unexpected ignored: %v", cfg.Ignored)
+	}
+	for key, want := range map[string]string{
+		"failure_threshold": "environment MDLINT_FAILURE_THRESHOLD",
+		"ignored[MD1400]":   "environment MDLINT_IGNORED",
+		"ignored[MD1500]":   filepath.Join(dir, ProjectFile),
+		"severity.MD1800":   "environment MDLINT_SEVERITY_MD1800",
+		"output.color":      SourceCLI,
+		"spell.lang":        "",
+	} {
+		if got := cfg.Sources[key]; got != want {
+			t.Errorf("source of %s: got %q want %q", key, got, want)
+		}
+	}
+
+	// LoadFile layers the environment above the named file too.
+	cfg, err = LoadFile(Config{}, filepath.Join(dir, ProjectFile))
+	if err != nil || cfg.Output.Format != "text" || cfg.Output.Color != "always" {
+		t.Fatalf("LoadFile: %+v %v", cfg.Output, err)
+	}
+}
+
+// TestEnvErrors verifies that invalid values name their variable and that
+// unknown variables are reported with a suggestion.
+func TestEnvErrors(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	for name, tc := range map[string]struct{ value, msg string }{
+		"MDLINT_OUTPUT_FORMAT":       {"jsno", `environment: MDLINT_OUTPUT_FORMAT: invalid output format "jsno" (did you mean "json"?)`},
+		"MDLINT_SEVERITY_MD1000":     {"warn", `environment: MDLINT_SEVERITY_MD1000: invalid severity "warn"`},
+		"MDLINT_HEADING_ALLOW_MIXED": {"maybe", "environment: MDLINT_HEADING_ALLOW_MIXED: cannot unmarshal"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(name, tc.value)
+			if _, err := Load(Config{}, ""); err == nil || !strings.Contains(err.Error(), tc.msg) {
+				t.Fatalf("expected error containing %q, got %v", tc.msg, err)
+			}
+		})
+	}
+
+	warnings := withWarnings(t)
+	t.Setenv("MDLINT_FAILURE_THRESHOLDS", "error")
+	t.Setenv("MDLINT_VERSION", "2")
+	if _, err := Load(Config{}, ""); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	want := []string{
+		`unknown environment variable MDLINT_FAILURE_THRESHOLDS (did you mean "MDLINT_FAILURE_THRESHOLD"?)`,
+		"unknown environment variable MDLINT_VERSION",
+	}
+	if !reflect.DeepEqual(*warnings, want) {
+		t.Fatalf("unexpected warnings: %q", *warnings)
+	}
+}
@@ -0,0 +1,254 @@
+// Copyright 2024 The mdlint Authors
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Error is an invalid configuration, located in the file it was read from
+// when known.
+type Error struct {
+	// File is the configuration file, or empty when the configuration did
+	// not come from a file.
+	File string
+	// Line and Column are the 1-based position of the offending key or
+	// value, or zero when unknown.
+	Line, Column int
+	// Err describes the problem.
+	Err error
+
+	// key is the path of the offending key from the root of the
+	// configuration, used to locate it in the file.
+	key []string
+}
+
+// Error returns the message prefixed with the file and position, as in
+// "path:line:column: message". Unknown parts are left out.
+func (e *Error) Error() string {
+	switch {
+	case e.File != "" && e.Column > 0:
+		return fmt.Sprintf("%s:%d:%d: %v", e.File, e.Line, e.Column, e.Err)
+	case e.File != "" && e.Line > 0:
+		return fmt.Sprintf("%s:%d: %v", e.File, e.Line, e.Err)
+	case e.File != "":
+		return fmt.Sprintf("%s: %v", e.File, e.Err)
+	case e.Column > 0:
+		return fmt.Sprintf("line %d column %d: %v", e.Line, e.Column, e.Err)
+	case e.Line > 0:
+		return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+	}
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error { return e.Err }
+
+// keyErrorf returns an error about the value of key, to be located by locate.
+func keyErrorf(key []string, format string, args ...any) *Error {
+	return &Error{Err: fmt.Errorf(format, args...), key: key}
+}
+
+// nodeErrorf returns an error located at the node n.
+func nodeErrorf(n *yaml.Node, key []string, format string, args ...any) *Error {
+	return &Error{Line: n.Line, Column: n.Column, Err: fmt.Errorf(format, args...), key: key}
+}
+
+// within prefixes the message of err with prefix and its key with parent,
+// for errors found in a nested section.
+func within(err error, prefix string, parent ...string) error {
+	e, ok := err.(*Error)
+	if !ok {
+		return fmt.Errorf("%s%w", prefix, err)
+	}
+	e.Err = fmt.Errorf("%s%w", prefix, e.Err)
+	e.key = append(append([]string(nil), parent...), e.key...)
+	return e
+}
+
+// locate returns err as an *Error naming file, positioned at its key within
+// the document doc when it has no position yet. Errors wrapping an *Error
+// have already been formatted, so they are wrapped without a position.
+func locate(err error, file string, doc *yaml.Node) *Error {
+	e, ok := err.(*Error)
+	if !ok {
+		return &Error{File: file, Err: err}
+	}
+	e.File = file
+	if e.Line == 0 {
+		if n := find(doc, e.key); n != nil {
+			e.Line, e.Column = n.Line, n.Column
+		}
+	}
+	return e
+}
+
+// find returns the node of the last key of path within doc, or the node of
+// its deepest key present.
+func find(doc *yaml.Node, path []string) *yaml.Node {
+	n := doc
+	if n != nil && n.Kind == yaml.DocumentNode && len(n.Content) > 0 {
+		n = n.Content[0]
+	}
+	var found *yaml.Node
+	for _, k := range path {
+		if n == nil || n.Kind != yaml.MappingNode {
+			break
+		}
+		var next *yaml.Node
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if n.Content[i].Value == k {
+				found, next = n.Content[i], n.Content[i+1]
+				break
+			}
+		}
+		n = next
+	}
+	return found
+}
+
+// yamlLine matches the position prefix of yaml.v3 error messages.
+var yamlLine = regexp.MustCompile(`^(?:yaml: )?line (\d+): `)
+
+// yamlError converts a yaml.v3 syntax or type error into an *Error carrying
+// its line. Only the first of several type errors is kept.
+func yamlError(err error) error {
+	msg := err.Error()
+	var te *yaml.TypeError
+	if errors.As(err, &te) && len(te.Errors) > 0 {
+		msg = te.Errors[0]
+	}
+	m := yamlLine.FindStringSubmatch(msg)
+	if m == nil {
+		return err
+	}
+	line, _ := strconv.Atoi(m[1])
+	return &Error{Line: line, Err: errors.New(strings.TrimPrefix(msg, m[0]))}
+}
+
+// checkKeys reports keys of the mapping n that the struct or map type t does
+// not define, suggesting the closest known key. Rules sections are checked by
+// validateRules once decoded.
+func checkKeys(n *yaml.Node, t reflect.Type, path []string) error {
+	if n.Kind == yaml.DocumentNode {
+		if len(n.Content) == 0 {
+			return nil
+		}
+		n = n.Content[0]
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if n.Kind != yaml.MappingNode || t == rulesType {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+		keyPath := append(path[:len(path):len(path)], key.Value)
+		var ft reflect.Type
+		switch t.Kind() {
+		case reflect.Map:
+			ft = t.Elem()
+		case reflect.Struct:
+			f, ok := fieldByName(t, key.Value)
+			if !ok {
+				return nodeErrorf(key, keyPath, "unknown key %q%s", strings.Join(keyPath, "."),
+					didYouMean(key.Value, optionNames(t)))
+			}
+			ft = f.Type
+		default:
+			return nil
+		}
+		if err := checkKeys(value, ft, keyPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fieldByName returns the field of the struct t named name by its yaml tag.
+func fieldByName(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if tag == "" {
+			tag = strings.ToLower(f.Name)
+		}
+		if f.IsExported() && tag != "-" && tag == name {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// didYouMean returns a hint naming the candidate closest to s, such as
+// ` (did you mean "warning"?)`, or "" when none is close enough. Candidates
+// starting with s, ignoring case, or within a few edits of it are close; the
+// number of edits allowed grows with the length of s.
+func didYouMean(s string, candidates []string) string {
+	sorted := append([]string(nil), candidates...)
+	sort.Strings(sorted)
+	lower := strings.ToLower(s)
+	limit := min(len(s)/2, 3)
+	best, bestDist, bestPrefix := "", -1, 0
+	for _, c := range sorted {
+		lc := strings.ToLower(c)
+		d := distance(lower, lc)
+		if len(lower) >= 2 && strings.HasPrefix(lc, lower) {
+			d = 1
+		}
+		if d > limit {
+			continue
+		}
+		// Among equally close candidates, prefer the one sharing the
+		// longest prefix with s.
+		prefix := commonPrefix(lower, lc)
+		if bestDist < 0 || d < bestDist || (d == bestDist && prefix > bestPrefix) {
+			best, bestDist, bestPrefix = c, d, prefix
+		}
+	}
+	if best == "" || best == s {
+		return ""
+	}
+	return fmt.Sprintf(" (did you mean %q?)", best)
+}
+
+// distance returns the Levenshtein distance between a and b.
+func distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+// commonPrefix returns the length in bytes of the common prefix of a and b.
+func commonPrefix(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
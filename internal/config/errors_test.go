@@ -0,0 +1,84 @@
+// Copyright 2025 Sam Caldwell
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestErrorPositions verifies that errors in configuration files name the
+// file, line and column of the offending key with a suggestion.
+func TestErrorPositions(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := t.TempDir()
+	for _, tc := range []struct {
+		name, src, want string
+	}{
+		{"sev.yaml", "version: 1\nseverity:\n  MD1000: warn\n", `:3:3: invalid severity "warn" (did you mean "warning"?)`},
+		{"key.yaml", "version: 1\nheading:\n  stlye: atx\n", `:3:3: unknown key "heading.stlye" (did you mean "style"?)`},
+		{"top.yaml", "version: 1\nignore: [MD1000]\n", `:2:1: unknown key "ignore" (did you mean "ignored"?)`},
+		{"enum.yaml", "version: 1\noutput:\n  format: jsno\n", `:3:3: invalid output format "jsno" (did you mean "json"?)`},
+		{"version.yaml", "version: 2\n", `:1:1: unsupported version 2`},
+		{"rule.yaml", "version: 1\nrules:\n  TEST:\n", `:3:3: rules: unknown rule "TEST" (did you mean "TEST1"?)`},
+		{"sevid.yaml", "version: 1\nseverity:\n  MD180: error\n", `:3:3: severity: unknown rule "MD180" (did you mean "MD1800"?)`},
+		{"ignored.yaml", "version: 1\nignored: [MD14000]\n", `:2:1: ignored: unknown rule "MD14000" (did you mean "MD1400"?)`},
+		{"pathid.yaml", "version: 1\npaths:\n  docs/:\n    severity:\n      md1500: error\n", `:5:7: paths "docs/": severity: unknown rule "md1500" (did you mean "MD1500"?)`},
+		{"option.yaml", "version: 1\npaths:\n  docs/:\n    rules:\n      TEST1: {limt: 1}\n", `:5:15: paths "docs/": rules.TEST1: unknown option "limt"`},
+		{"type.yaml", "version: 1\nignored: {a: b}\n", `:2: cannot unmarshal !!map into []string`},
+		{"syntax.yaml", "version: 1\n  bad: [\n", `:2: mapping values are not allowed`},
+		{"sev.toml", "version = 1\n[severity]\nMD1000 = \"eror\"\n", `:3:1: invalid severity "eror" (did you mean "error"?)`},
+		{"bad.toml", "version = 1\n[heading]\n[heading]\n", `:3:2: table heading already exists`},
+	} {
+		path := filepath.Join(dir, tc.name)
+		writeFile(t, path, tc.src)
+		_, err := LoadFile(Config{}, path)
+		var cfgErr *Error
+		if !errors.As(err, &cfgErr) || cfgErr.File != path || !strings.Contains(err.Error(), path+tc.want) {
+			t.Errorf("%s: expected error containing %q, got %v", tc.name, path+tc.want, err)
+		}
+	}
+}
+
+// TestErrorLayers verifies that Load names the layer whose file is invalid.
+func TestErrorLayers(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	user := filepath.Join(home, "mdlint", "config.yaml")
+	writeFile(t, user, "version: 1\nfailure_threshold: fatal\n")
+	if _, err := Load(Config{}, ""); err == nil || !strings.HasPrefix(err.Error(), "user configuration: "+user+":2:1: ") {
+		t.Fatalf("expected user configuration error, got %v", err)
+	}
+
+	writeFile(t, user, "version: 1\n")
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".git", "HEAD"), "")
+	project := filepath.Join(dir, ProjectFile)
+	writeFile(t, project, "version: 1\nheading:\n  style: setx\n")
+	if _, err := Load(Config{}, dir); err == nil ||
+		!strings.HasPrefix(err.Error(), "project configuration: "+project+":3:3: ") ||
+		!strings.Contains(err.Error(), `(did you mean "setext"?)`) {
+		t.Fatalf("expected project configuration error, got %v", err)
+	}
+}
+
+// TestDidYouMean covers the suggestion heuristics.
+func TestDidYouMean(t *testing.T) {
+	candidates := []string{"suggestion", "warning", "error"}
+	for s, want := range map[string]string{
+		"warn":      ` (did you mean "warning"?)`,
+		"Warning":   ` (did you mean "warning"?)`,
+		"erorr":     ` (did you mean "error"?)`,
+		"sugestion": ` (did you mean "suggestion"?)`,
+		"fatal":     "",
+		"e":         "",
+		"warning":   "",
+	} {
+		if got := didYouMean(s, candidates); got != want {
+			t.Errorf("%q: got %q want %q", s, got, want)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2024 The mdlint Authors
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// layer is a configuration read from a file or preset, with its source for
+// Config.Sources.
+type layer struct {
+	cfg    Config
+	source string
+}
+
+// readLayers reads the configuration file at path together with everything
+// it extends. Layers are returned in merge order: every extended
+// configuration precedes the configuration extending it, and entries of
+// extends are merged in the order they are listed.
+func readLayers(path string) ([]layer, error) {
+	return extendFile(path, nil)
+}
+
+// isPreset reports whether an extends entry names a built-in preset rather
+// than a file. Files are named by paths with a directory or a YAML extension.
+func isPreset(name string) bool {
+	return !strings.ContainsAny(name, `/\`) && filepath.Ext(name) == ""
+}
+
+// extendFile reads the file at path and the configurations it extends.
+// chain holds the files and presets being resolved, to detect cycles.
+func extendFile(path string, chain []string) ([]layer, error) {
+	cfg, err := readConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	return extend(layer{cfg, path}, abs, filepath.Dir(path), chain)
+}
+
+// extendPreset parses the preset name and the presets it extends.
+func extendPreset(name string, chain []string) ([]layer, error) {
+	src, ok := presets[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown preset %q%s", name, didYouMean(name, sortedKeys(presets)))
+	}
+	cfg, err := parseYAML([]byte(src))
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("preset %q: %w", name, err)
+	}
+	return extend(layer{cfg, "preset " + name}, "preset "+name, "", chain)
+}
+
+// extend returns the layers extended by l followed by l itself. id
+// identifies l in chain, and relative file names are resolved against dir;
+// presets, which have no directory, may only extend other presets.
+func extend(l layer, id, dir string, chain []string) ([]layer, error) {
+	for i, c := range chain {
+		if c == id {
+			return nil, fmt.Errorf("extends cycle: %s", strings.Join(append(chain[i:], id), " -> "))
+		}
+	}
+	chain = append(chain[:len(chain):len(chain)], id)
+	var result []layer
+	for _, entry := range l.cfg.Extends {
+		name := entry
+		var (
+			ls  []layer
+			err error
+		)
+		switch {
+		case isPreset(name):
+			ls, err = extendPreset(name, chain)
+		case dir == "":
+			err = fmt.Errorf("file %q cannot be extended by a preset", name)
+		default:
+			if !filepath.IsAbs(name) {
+				name = filepath.Join(dir, name)
+			}
+			ls, err = extendFile(name, chain)
+		}
+		if err != nil {
+			// A missing extended file is an error of the extending
+			// configuration, so it must not satisfy os.ErrNotExist checks
+			// meant for the extending file itself.
+			if errors.Is(err, os.ErrNotExist) {
+				err = errors.New(err.Error())
+			}
+			return nil, fmt.Errorf("%s: extends %q: %w", l.source, entry, err)
+		}
+		result = append(result, ls...)
+	}
+	return append(result, l), nil
+}
+
+// mergeLayers merges ls into c in order, recording their sources.
+func (c *Config) mergeLayers(ls []layer) {
+	for _, l := range ls {
+		merge(c, l.cfg)
+		c.record(l.cfg, l.source)
+	}
+}
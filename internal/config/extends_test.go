@@ -0,0 +1,90 @@
+// Copyright 2025 Sam Caldwell
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// withPreset registers a preset for the duration of the test.
+func withPreset(t *testing.T, name, src string) {
+	t.Helper()
+	presets[name] = src
+	t.Cleanup(func() { delete(presets, name) })
+}
+
+// TestExtends verifies that extended files and presets are merged before the
+// extending file, in the order they are listed.
+func TestExtends(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	withPreset(t, "test-base", "version: 1\nignored: [MD1000]\nseverity:\n  MD1800: suggestion\n")
+	dir := t.TempDir()
+	shared := filepath.Join(dir, "shared", "base.yaml")
+	writeFile(t, shared, "version: 1\nextends: [test-base]\nignored: [MD1400]\nseverity:\n  MD1800: error\n  MD1500: error\n")
+	proj := filepath.Join(dir, "proj", ProjectFile)
+	writeFile(t, proj, "version: 1\nextends: [../shared/base.yaml]\nseverity:\n  MD1500: warning\n")
+
+	cfg, err := LoadFile(Config{}, proj)
+	if err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	if !reflect.DeepEqual(cfg.Ignored, []string{"MD1000", "MD1400"}) {
+		t.Fatalf("unexpected ignored: %v", cfg.Ignored)
+	}
+	want := map[string]Severity{"MD1800": "error", "MD1500": "warning"}
+	if !reflect.DeepEqual(cfg.Severity, want) {
+		t.Fatalf("unexpected severity: %v", cfg.Severity)
+	}
+	for key, src := range map[string]string{
+		"ignored[MD1000]": "preset test-base",
+		"ignored[MD1400]": shared,
+		"severity.MD1500": proj,
+	} {
+		if got := cfg.Sources[key]; got != src {
+			t.Errorf("source of %s = %q, want %q", key, got, src)
+		}
+	}
+
+	// Discovered project files resolve extends as well.
+	writeFile(t, filepath.Join(dir, "proj", ".git", "HEAD"), "")
+	cfg, err = Load(Config{}, filepath.Join(dir, "proj"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !reflect.DeepEqual(cfg.Ignored, []string{"MD1000", "MD1400"}) {
+		t.Fatalf("unexpected ignored: %v", cfg.Ignored)
+	}
+}
+
+// TestExtendsErrors covers cycles, unknown presets and missing files.
+func TestExtendsErrors(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	withPreset(t, "test-loop", "version: 1\nextends: [test-loop]\n")
+	withPreset(t, "test-file", "version: 1\nextends: [base.yaml]\n")
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.yaml"), "version: 1\nextends: [b.yaml]\n")
+	writeFile(t, filepath.Join(dir, "b.yaml"), "version: 1\nextends: [./a.yaml]\n")
+
+	for src, msg := range map[string]string{
+		"version: 1\nextends: [a.yaml]\n":       "extends cycle",
+		"version: 1\nextends: [test-loop]\n":    "extends cycle: preset test-loop -> preset test-loop",
+		"version: 1\nextends: [nope]\n":         `unknown preset "nope"`,
+		"version: 1\nextends: [strickt]\n":      `unknown preset "strickt" (did you mean "strict"?)`,
+		"version: 1\nextends: [test-file]\n":    "cannot be extended by a preset",
+		"version: 1\nextends: [missing.yaml]\n": `extends "missing.yaml"`,
+	} {
+		path := filepath.Join(dir, ProjectFile)
+		writeFile(t, path, src)
+		if _, err := LoadFile(Config{}, path); err == nil || !strings.Contains(err.Error(), msg) {
+			t.Errorf("%q: expected error containing %q, got %v", src, msg, err)
+		}
+		writeFile(t, filepath.Join(dir, ".git", "HEAD"), "")
+		if _, err := Load(Config{}, dir); err == nil {
+			t.Errorf("%q: expected discovery error", src)
+		}
+	}
+}
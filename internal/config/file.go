@@ -0,0 +1,172 @@
+// Copyright 2024 The mdlint Authors
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FrontMatterKey is the reserved front-matter key holding a document's own
+// configuration.
+const FrontMatterKey = "mdlint"
+
+// FileConfig holds the settings a single document may override for itself
+// under the FrontMatterKey of its front matter, for example:
+//
+//	---
+//	title: Guide
+//	mdlint:
+//	  ignored: [MD1000]
+//	  heading:
+//	    style: setext
+//	---
+type FileConfig struct {
+	Ignored  []string               `yaml:"ignored"`
+	Severity map[string]Severity    `yaml:"severity"`
+	Spell    SpellConfig            `yaml:"spell"`
+	Heading  HeadingConfig          `yaml:"heading"`
+	Rules    map[string]RuleOptions `yaml:"rules"`
+}
+
+// ParseFrontMatter extracts the per-file configuration from the YAML front
+// matter of a document. Keys other than FrontMatterKey belong to the
+// document and are ignored, while unknown keys below it are rejected. Front
+// matter that is not valid YAML, such as templated front matter, is only
+// rejected when its FrontMatterKey section is invalid. ok is false when the
+// front matter has no FrontMatterKey. Invalid settings are reported as an
+// *Error whose line, when known, counts from the first line of data.
+func ParseFrontMatter(data []byte) (fc FileConfig, ok bool, err error) {
+	var doc yaml.Node
+	if yaml.Unmarshal(data, &doc) != nil {
+		section := frontMatterSection(data)
+		if section == nil {
+			return FileConfig{}, false, nil
+		}
+		doc = yaml.Node{}
+		if err := yaml.Unmarshal(section, &doc); err != nil {
+			return FileConfig{}, false, within(yamlError(err), "front matter: ")
+		}
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return FileConfig{}, false, nil
+	}
+	root := doc.Content[0]
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != FrontMatterKey {
+			continue
+		}
+		section := root.Content[i+1]
+		err := checkKeys(section, reflect.TypeOf(fc), nil)
+		if err == nil {
+			if err = section.Decode(&fc); err != nil {
+				err = yamlError(err)
+			}
+		}
+		if err == nil {
+			err = fc.Validate()
+		}
+		if err != nil {
+			return FileConfig{}, false, within(locate(err, "", section), fmt.Sprintf("front matter %s: ", FrontMatterKey))
+		}
+		return fc, true, nil
+	}
+	return FileConfig{}, false, nil
+}
+
+// frontMatterSection returns data with every line outside the top-level
+// FrontMatterKey entry blanked, keeping line numbers, or nil when data has no
+// such entry. The entry ends at the next line starting with a key.
+func frontMatterSection(data []byte) []byte {
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	out := make([]byte, 0, len(data))
+	in, found := false, false
+	for _, line := range lines {
+		content := bytes.TrimRight(line, "\r\n")
+		switch {
+		case bytes.HasPrefix(content, []byte(FrontMatterKey+":")):
+			rest := content[len(FrontMatterKey)+1:]
+			in = len(rest) == 0 || rest[0] == ' ' || rest[0] == '\t'
+			found = found || in
+		case len(content) > 0 && content[0] != ' ' && content[0] != '\t' && content[0] != '#':
+			in = false
+		}
+		if in {
+			out = append(out, line...)
+		} else if bytes.HasSuffix(line, []byte("\n")) {
+			out = append(out, '\n')
+		}
+	}
+	if !found {
+		return nil
+	}
+	return out
+}
+
+// Validate checks the rule IDs, severities, heading style and rule options of
+// fc.
+func (fc FileConfig) Validate() error {
+	return Config{Version: CurrentVersion(), Ignored: fc.Ignored, Severity: fc.Severity, Heading: fc.Heading, Rules: fc.Rules}.Validate()
+}
+
+// WithFile returns a copy of c with the per-file configuration fc merged on
+// top, using the same rules as merging configuration files. c itself is not
+// modified, so a shared configuration may be specialized per file.
+func (c Config) WithFile(fc FileConfig) Config {
+	out := c.clone()
+	layer := Config{
+		Ignored:  fc.Ignored,
+		Severity: fc.Severity,
+		Spell:    fc.Spell,
+		Heading:  fc.Heading,
+		Rules:    fc.Rules,
+	}
+	merge(&out, layer)
+	out.record(layer, SourceFrontMatter)
+	return out
+}
+
+// clone returns a copy of c sharing no slices or maps with it, so that merging
+// into the copy leaves c untouched.
+func (c Config) clone() Config {
+	out := c
+	out.Ignored = append([]string(nil), c.Ignored...)
+	out.Severity = cloneSeverity(c.Severity)
+	if c.Paths != nil {
+		out.Paths = make(map[string]PathConfig, len(c.Paths))
+		for k, v := range c.Paths {
+			out.Paths[k] = PathConfig{
+				Ignored:  append([]string(nil), v.Ignored...),
+				Severity: cloneSeverity(v.Severity),
+				Rules:    mergeRules(nil, v.Rules),
+			}
+		}
+	}
+	out.Rules = mergeRules(nil, c.Rules)
+	out.Spell.AddWords = append([]string(nil), c.Spell.AddWords...)
+	out.Spell.RejectWords = append([]string(nil), c.Spell.RejectWords...)
+	out.Spell.Filters = append([]string(nil), c.Spell.Filters...)
+	if c.Sources != nil {
+		out.Sources = make(map[string]string, len(c.Sources))
+		for k, v := range c.Sources {
+			out.Sources[k] = v
+		}
+	}
+	return out
+}
+
+// cloneSeverity returns a copy of m, or nil when m is nil.
+func cloneSeverity(m map[string]Severity) map[string]Severity {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]Severity, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
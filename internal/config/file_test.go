@@ -0,0 +1,81 @@
+// Copyright 2025 Sam Caldwell
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestParseFrontMatter verifies that the reserved key is decoded strictly
+// while the document's own keys are ignored.
+func TestParseFrontMatter(t *testing.T) {
+	src := "title: Guide\nmdlint:\n  ignored: [MD1000]\n  severity:\n    MD1800: error\n  spell:\n    add_words: [GoLand]\n  heading:\n    style: setext\n"
+	fc, ok, err := ParseFrontMatter([]byte(src))
+	if err != nil || !ok {
+		t.Fatalf("ParseFrontMatter: ok %v err %v", ok, err)
+	}
+	want := FileConfig{
+		Ignored:  []string{"MD1000"},
+		Severity: map[string]Severity{"MD1800": "error"},
+		Spell:    SpellConfig{AddWords: []string{"GoLand"}},
+		Heading:  HeadingConfig{Style: "setext"},
+	}
+	if !reflect.DeepEqual(fc, want) {
+		t.Fatalf("got %+v want %+v", fc, want)
+	}
+
+	// Front matter that is not YAML only needs a valid mdlint section.
+	src = "title: \"a: b\ntags: {{ .Tags }}\nmdlint:\n  ignored: [MD1000]\n# note\nlayout: post\n"
+	if fc, ok, err := ParseFrontMatter([]byte(src)); err != nil || !ok || !reflect.DeepEqual(fc.Ignored, []string{"MD1000"}) {
+		t.Fatalf("unparsable front matter: got %+v ok %v err %v", fc, ok, err)
+	}
+
+	for _, src := range []string{"", "title: Guide\n", "- a\n- b\n", "title: \"a: b\n", "title: {{ .Title }}\nmdlintx: 1\n"} {
+		if _, ok, err := ParseFrontMatter([]byte(src)); ok || err != nil {
+			t.Fatalf("%q: expected no configuration, got ok %v err %v", src, ok, err)
+		}
+	}
+
+	for src, msg := range map[string]string{
+		"mdlint:\n  output:\n    format: text\n":   `line 2 column 3: front matter mdlint: unknown key "output"`,
+		"mdlint:\n  severity:\n    MD1000: loud\n": `invalid severity "loud"`,
+		"mdlint:\n  heading:\n    style: fancy\n":  `invalid heading style "fancy"`,
+		"mdlint: [\n":                   "front matter",
+		"mdlint:\n  ignored: [MD100]\n": `front matter mdlint: ignored: unknown rule "MD100" (did you mean "MD1000"?)`,
+		"title: \"a: b\nmdlint:\n  heading:\n    style: fancy\n": `line 4 column 5: front matter mdlint: invalid heading style "fancy"`,
+		"title: {{ .Title }}\nmdlint:\n  ignored: [MD1000\n":     "front matter: did not find expected",
+	} {
+		if _, _, err := ParseFrontMatter([]byte(src)); err == nil || !strings.Contains(err.Error(), msg) {
+			t.Fatalf("%q: expected error containing %q, got %v", src, msg, err)
+		}
+	}
+}
+
+// TestWithFile verifies that per-file settings are merged on top of a copy of
+// the configuration.
+func TestWithFile(t *testing.T) {
+	base := Config{
+		Version:  1,
+		Ignored:  make([]string, 1, 4),
+		Severity: map[string]Severity{"MD1000": "warning"},
+	}
+	base.Ignored[0] = "MD1400"
+	got := base.WithFile(FileConfig{
+		Ignored:  []string{"MD1500"},
+		Severity: map[string]Severity{"MD1000": "error"},
+		Heading:  HeadingConfig{Style: "atx"},
+	})
+	if !reflect.DeepEqual(got.Ignored, []string{"MD1400", "MD1500"}) {
+		t.Fatalf("unexpected ignored: %v", got.Ignored)
+	}
+	if got.Severity["MD1000"] != "error" || got.Heading.Style != "atx" {
+		t.Fatalf("unexpected merge: %+v", got)
+	}
+	// Appending to the copy must not write into the spare capacity of base.
+	if base.Severity["MD1000"] != "warning" || base.Ignored[:2][1] != "" || base.Heading.Style != "" {
+		t.Fatalf("base configuration modified: %+v", base)
+	}
+}
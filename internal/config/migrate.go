@@ -0,0 +1,219 @@
+// Copyright 2024 The mdlint Authors
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// currentVersion is the version of the configuration format. Files without a
+// version key are version 0 and only need the key added; a version that
+// renames keys will add the rewriting here.
+const currentVersion = 1
+
+// CurrentVersion returns the version of the configuration format: the only
+// one Validate accepts and the one written by Migrate.
+func CurrentVersion() int {
+	return currentVersion
+}
+
+// Warn, when set, is called with a message for every deprecated setting read
+// from a configuration file, such as a missing version key, and for every
+// unknown MDLINT_ environment variable. Deprecated files are upgraded in
+// memory and `mdlint config migrate` rewrites them.
+var Warn func(msg string)
+
+// change is a modification made by a migration, positioned in the file
+// before the migration.
+type change struct {
+	line, column int
+	msg          string
+}
+
+// String returns the change prefixed with its position.
+func (c change) String() string {
+	return fmt.Sprintf("%d:%d: %s", c.line, c.column, c.msg)
+}
+
+// upgrade migrates the configuration document doc in place from its version
+// to CurrentVersion, returning the changes made in order. Documents of the
+// current or a newer version are left for Validate to accept or reject.
+func upgrade(doc *yaml.Node) []change {
+	root := doc
+	if root.Kind == yaml.DocumentNode {
+		if len(root.Content) == 0 {
+			return nil
+		}
+		root = root.Content[0]
+	}
+	if root.Kind != yaml.MappingNode {
+		return nil
+	}
+	version := 0
+	versionNode := lookup(root, "version")
+	if versionNode != nil {
+		v, err := strconv.Atoi(versionNode.Value)
+		if err != nil || versionNode.Kind != yaml.ScalarNode {
+			// Left for decoding to report.
+			return nil
+		}
+		version = v
+	}
+	if version < 0 || version >= CurrentVersion() {
+		return nil
+	}
+
+	current := strconv.Itoa(CurrentVersion())
+	var changes []change
+	if versionNode == nil {
+		changes = append(changes, change{root.Line, root.Column, "version is missing; version " + current + " assumed"})
+		key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "version"}
+		if len(root.Content) > 0 {
+			// Keep a comment heading the file above the new first key.
+			key.HeadComment, root.Content[0].HeadComment = root.Content[0].HeadComment, ""
+		}
+		root.Content = append([]*yaml.Node{key, {Kind: yaml.ScalarNode, Tag: "!!int", Value: current}}, root.Content...)
+	} else {
+		changes = append(changes, change{versionNode.Line, versionNode.Column,
+			fmt.Sprintf("version %d is upgraded to version %s", version, current)})
+		versionNode.Value, versionNode.Tag, versionNode.Style = current, "!!int", 0
+	}
+	return changes
+}
+
+// warnDeprecated reports the changes an in-memory upgrade of the file path
+// made through Warn.
+func warnDeprecated(path string, changes []change) {
+	if Warn == nil {
+		return
+	}
+	for _, c := range changes {
+		Warn(fmt.Sprintf("%s:%s; run \"mdlint config migrate\" to update the file", path, c))
+	}
+}
+
+// Migrate upgrades the configuration file at path to CurrentVersion. It
+// returns the new contents of the file and a description of every change,
+// positioned in the original file, or no changes when the file is current.
+// Comments, key order and formatting of YAML files are preserved where
+// possible, and JSON files are rewritten as indented JSON. The file itself
+// is not modified.
+func Migrate(path string) ([]byte, []string, error) {
+	data, doc, err := readNode(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	changes := upgrade(doc)
+	// Only valid files are rewritten, which also rejects newer versions.
+	cfg, err := decodeConfig(doc)
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if err != nil {
+		return nil, nil, locate(err, path, doc)
+	}
+	if len(changes) == 0 {
+		return data, nil, nil
+	}
+	var out bytes.Buffer
+	switch filepath.Ext(path) {
+	case ".toml":
+		return nil, nil, &Error{File: path, Err: errors.New("TOML files cannot be migrated automatically; apply the changes by hand")}
+	case ".json":
+		if err := encodeJSON(&out, doc, ""); err != nil {
+			return nil, nil, err
+		}
+		out.WriteByte('\n')
+	default:
+		enc := yaml.NewEncoder(&out)
+		enc.SetIndent(indentOf(data))
+		if err := enc.Encode(doc); err != nil {
+			return nil, nil, err
+		}
+	}
+	msgs := make([]string, len(changes))
+	for i, c := range changes {
+		msgs[i] = c.String()
+	}
+	return out.Bytes(), msgs, nil
+}
+
+// indentOf returns the indentation of the first indented line of data, or
+// two spaces when there is none.
+func indentOf(data []byte) int {
+	for _, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if n := len(line) - len(trimmed); n > 0 && trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			return n
+		}
+	}
+	return 2
+}
+
+// encodeJSON writes the YAML node n as JSON, keeping the order of keys.
+func encodeJSON(buf *bytes.Buffer, n *yaml.Node, indent string) error {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			buf.WriteString("null")
+			return nil
+		}
+		return encodeJSON(buf, n.Content[0], indent)
+	case yaml.AliasNode:
+		return encodeJSON(buf, n.Alias, indent)
+	case yaml.MappingNode, yaml.SequenceNode:
+		open, close, step := "[", "]", 1
+		if n.Kind == yaml.MappingNode {
+			open, close, step = "{", "}", 2
+		}
+		if len(n.Content) == 0 {
+			buf.WriteString(open + close)
+			return nil
+		}
+		buf.WriteString(open)
+		for i := 0; i < len(n.Content); i += step {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString("\n" + indent + "  ")
+			if step == 2 {
+				key, _ := json.Marshal(n.Content[i].Value)
+				buf.Write(key)
+				buf.WriteString(": ")
+			}
+			if err := encodeJSON(buf, n.Content[i+step-1], indent+"  "); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("\n" + indent + close)
+		return nil
+	}
+	switch n.ShortTag() {
+	case "!!int", "!!float", "!!bool", "!!null":
+		var v any
+		if err := n.Decode(&v); err != nil {
+			return err
+		}
+		out, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		buf.Write(out)
+		return nil
+	}
+	out, err := json.Marshal(n.Value)
+	if err != nil {
+		return err
+	}
+	buf.Write(out)
+	return nil
+}
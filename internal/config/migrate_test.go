@@ -0,0 +1,127 @@
+// Copyright 2025 Sam Caldwell
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// withWarnings collects the messages passed to Warn during the test.
+func withWarnings(t *testing.T) *[]string {
+	t.Helper()
+	var msgs []string
+	old := Warn
+	Warn = func(msg string) { msgs = append(msgs, msg) }
+	t.Cleanup(func() { Warn = old })
+	return &msgs
+}
+
+const oldConfig = `# project settings
+failure_threshold: error # stricter in CI
+paths:
+  docs/**:
+    # generated reference
+    ignored: [MD1000]
+`
+
+// TestMigrate verifies that a missing version key is added above the first
+// key, keeping comments and order.
+func TestMigrate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ProjectFile)
+	writeFile(t, path, oldConfig)
+
+	data, changes, err := Migrate(path)
+	if err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	want := `# project settings
+version: 1
+failure_threshold: error # stricter in CI
+paths:
+  docs/**:
+    # generated reference
+    ignored: [MD1000]
+`
+	if string(data) != want {
+		t.Fatalf("got\n%s\nwant\n%s", data, want)
+	}
+	wantChanges := []string{"2:1: version is missing; version 1 assumed"}
+	if !reflect.DeepEqual(changes, wantChanges) {
+		t.Fatalf("unexpected changes: %q", changes)
+	}
+	if got, _ := os.ReadFile(path); string(got) != oldConfig {
+		t.Fatalf("file modified: %s", got)
+	}
+
+	// A current file is returned unchanged.
+	writeFile(t, path, want)
+	if data, changes, err := Migrate(path); err != nil || changes != nil || string(data) != want {
+		t.Fatalf("expected no changes, got %q %v", changes, err)
+	}
+
+	// An explicit older version is bumped in place.
+	writeFile(t, path, "version: 0 # legacy\nignored: [MD1000]\n")
+	data, changes, err = Migrate(path)
+	if err != nil || string(data) != "version: 1 # legacy\nignored: [MD1000]\n" ||
+		!reflect.DeepEqual(changes, []string{"1:10: version 0 is upgraded to version 1"}) {
+		t.Fatalf("unexpected migration %q %v:\n%s", changes, err, data)
+	}
+}
+
+// TestMigrateVersion verifies that a missing version key is added and that
+// JSON files stay JSON.
+func TestMigrateVersion(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".mdlintrc.json")
+	writeFile(t, path, `{"ignored": ["MD1000"], "output": {"color": "never"}}`)
+	data, changes, err := Migrate(path)
+	if err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	want := "{\n  \"version\": 1,\n  \"ignored\": [\n    \"MD1000\"\n  ],\n  \"output\": {\n    \"color\": \"never\"\n  }\n}\n"
+	if string(data) != want || len(changes) != 1 || !strings.Contains(changes[0], "version is missing") {
+		t.Fatalf("unexpected migration %q:\n%s", changes, data)
+	}
+}
+
+// TestMigrateErrors covers TOML files and invalid files.
+func TestMigrateErrors(t *testing.T) {
+	dir := t.TempDir()
+	for name, tc := range map[string]struct{ src, msg string }{
+		"old.toml":     {"failure_threshold = \"error\"\n", "TOML files cannot be migrated"},
+		"newer.yaml":   {"version: 2\n", "unsupported version 2"},
+		"invalid.yaml": {"failure_threshold: fatal\n", `invalid failure threshold: invalid severity "fatal"`},
+	} {
+		path := filepath.Join(dir, name)
+		writeFile(t, path, tc.src)
+		if _, _, err := Migrate(path); err == nil || !strings.Contains(err.Error(), path) || !strings.Contains(err.Error(), tc.msg) {
+			t.Errorf("%s: expected error containing %q, got %v", name, tc.msg, err)
+		}
+	}
+}
+
+// TestDeprecationWarnings verifies that older files are upgraded in memory
+// when loaded, reporting every deprecated setting through Warn.
+func TestDeprecationWarnings(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	warnings := withWarnings(t)
+	path := filepath.Join(t.TempDir(), ProjectFile)
+	writeFile(t, path, oldConfig)
+
+	cfg, err := LoadFile(Config{}, path)
+	if err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	if cfg.FailureThreshold != "error" || !reflect.DeepEqual(cfg.Paths["docs/**"].Ignored, []string{"MD1000"}) {
+		t.Fatalf("unexpected configuration: %+v", cfg)
+	}
+	if len(*warnings) != 1 || !strings.HasPrefix((*warnings)[0], path+":2:1: version is missing; version 1 assumed") ||
+		!strings.Contains((*warnings)[0], "mdlint config migrate") {
+		t.Fatalf("unexpected warnings: %q", *warnings)
+	}
+}
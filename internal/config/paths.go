@@ -0,0 +1,103 @@
+// Copyright 2024 The mdlint Authors
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/asymmetric-effort/mdlint/internal/glob"
+)
+
+// Resolve returns the effective configuration of the file at path before
+// front matter is applied. When c was loaded with Load, the project files
+// discovered for the file's directory take the place of those of c's
+// directory. Matching path overrides are then applied as by ForFile.
+func (c Config) Resolve(path string) (Config, error) {
+	if c.tree != nil {
+		var err error
+		if c, err = c.tree.resolve(filepath.Dir(path)); err != nil {
+			return Config{}, err
+		}
+	}
+	return c.ForFile(path), nil
+}
+
+// ForFile returns the effective configuration of the file at path: the global
+// ignored rules, severities and rule options layered with every entry of
+// Paths whose pattern matches the file. Matching entries are applied from the
+// least to the most specific pattern, so the most specific one decides a
+// rule's severity. Patterns are matched against path relative to Root; files
+// outside Root match no pattern. c itself is not modified.
+func (c Config) ForFile(path string) Config {
+	out := c.clone()
+	rel, ok := c.relative(path)
+	if !ok {
+		return out
+	}
+	var matched []string
+	for p := range c.Paths {
+		if glob.Match(p, rel) {
+			matched = append(matched, p)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return lessSpecific(matched[i], matched[j])
+	})
+	for _, p := range matched {
+		pc := c.Paths[p]
+		layer := Config{Ignored: pc.Ignored, Severity: pc.Severity, Rules: pc.Rules}
+		merge(&out, layer)
+		out.record(layer, fmt.Sprintf("paths %q", p))
+	}
+	return out
+}
+
+// relative returns path relative to Root with forward slashes. It reports
+// false when path lies outside Root.
+func (c Config) relative(path string) (string, bool) {
+	root := c.Root
+	if root == "" {
+		root = "."
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return "", false
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(absRoot, absPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// lessSpecific orders path patterns by specificity: patterns with fewer
+// literal segments are less specific, then shorter patterns. Ties are broken
+// lexically so that the order is deterministic.
+func lessSpecific(a, b string) bool {
+	if la, lb := literalSegments(a), literalSegments(b); la != lb {
+		return la < lb
+	}
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
+
+// literalSegments counts the segments of pattern without wildcards.
+func literalSegments(pattern string) int {
+	n := 0
+	for _, seg := range strings.Split(strings.Trim(pattern, "/"), "/") {
+		if seg != "" && !strings.ContainsAny(seg, "*?[") {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,71 @@
+// Copyright 2025 Sam Caldwell
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestForFile verifies that matching path overrides are layered from the
+// least to the most specific pattern.
+func TestForFile(t *testing.T) {
+	root := t.TempDir()
+	cfg := Config{
+		Version:  1,
+		Root:     root,
+		Ignored:  []string{"MD1500"},
+		Severity: map[string]Severity{"MD1000": "warning"},
+		Paths: map[string]PathConfig{
+			"docs/**":         {Ignored: []string{"MD1400"}, Severity: map[string]Severity{"MD1000": "error"}},
+			"docs/api/**":     {Severity: map[string]Severity{"MD1000": "suggestion"}},
+			"*.md":            {Severity: map[string]Severity{"MD1800": "error"}},
+			"docs/api/gen.md": {Ignored: []string{"MD1000"}},
+			"other/**/*.md":   {Ignored: []string{"MD1101"}},
+		},
+	}
+
+	got := cfg.ForFile(filepath.Join(root, "docs", "api", "ref.md"))
+	if !reflect.DeepEqual(got.Ignored, []string{"MD1500", "MD1400"}) {
+		t.Fatalf("unexpected ignored: %v", got.Ignored)
+	}
+	want := map[string]Severity{"MD1000": "suggestion", "MD1800": "error"}
+	if !reflect.DeepEqual(got.Severity, want) {
+		t.Fatalf("unexpected severity: %v", got.Severity)
+	}
+
+	got = cfg.ForFile(filepath.Join(root, "docs", "api", "gen.md"))
+	if !reflect.DeepEqual(got.Ignored, []string{"MD1500", "MD1400", "MD1000"}) {
+		t.Fatalf("unexpected ignored: %v", got.Ignored)
+	}
+
+	got = cfg.ForFile(filepath.Join(root, "docs", "guide.md"))
+	if got.Severity["MD1000"] != "error" {
+		t.Fatalf("unexpected severity: %v", got.Severity)
+	}
+
+	outside := filepath.Join(filepath.Dir(root), "docs", "x.md")
+	got = cfg.ForFile(outside)
+	if !reflect.DeepEqual(got.Ignored, []string{"MD1500"}) || got.Severity["MD1000"] != "warning" {
+		t.Fatalf("file outside root matched paths: %+v", got)
+	}
+	if cfg.Severity["MD1000"] != "warning" || len(cfg.Ignored) != 1 {
+		t.Fatalf("configuration modified: %+v", cfg)
+	}
+}
+
+// TestValidatePaths rejects malformed and negated path patterns.
+func TestValidatePaths(t *testing.T) {
+	for pattern, msg := range map[string]string{
+		"docs/[a": "invalid glob pattern",
+		"!docs":   "negated pattern",
+	} {
+		cfg := Config{Version: 1, Paths: map[string]PathConfig{pattern: {}}}
+		if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), msg) {
+			t.Fatalf("%q: expected error containing %q, got %v", pattern, msg, err)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2024 The mdlint Authors
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"embed"
+	"path"
+	"sort"
+	"strings"
+)
+
+// presetFiles holds the built-in presets, one YAML file per preset whose
+// leading comment describes it.
+//
+//go:embed presets/*.yaml
+var presetFiles embed.FS
+
+// presets maps preset names to their YAML source.
+var presets = loadPresets()
+
+// Preset describes a built-in configuration that extends or --preset may
+// name.
+type Preset struct {
+	Name        string
+	Description string
+}
+
+// loadPresets reads the embedded preset files.
+func loadPresets() map[string]string {
+	entries, err := presetFiles.ReadDir("presets")
+	if err != nil {
+		panic(err)
+	}
+	m := make(map[string]string, len(entries))
+	for _, e := range entries {
+		data, err := presetFiles.ReadFile(path.Join("presets", e.Name()))
+		if err != nil {
+			panic(err)
+		}
+		m[strings.TrimSuffix(e.Name(), ".yaml")] = string(data)
+	}
+	return m
+}
+
+// Presets returns the built-in presets sorted by name.
+func Presets() []Preset {
+	result := make([]Preset, 0, len(presets))
+	for name, src := range presets {
+		desc, _, _ := strings.Cut(src, "\n")
+		if !strings.HasPrefix(desc, "#") {
+			desc = ""
+		}
+		result = append(result, Preset{Name: name, Description: strings.TrimSpace(strings.TrimPrefix(desc, "#"))})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
@@ -0,0 +1,51 @@
+// Copyright 2025 Sam Caldwell
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestPresets verifies that every built-in preset is described and valid.
+func TestPresets(t *testing.T) {
+	var names []string
+	for _, p := range Presets() {
+		names = append(names, p.Name)
+		if p.Description == "" {
+			t.Errorf("preset %s has no description", p.Name)
+		}
+		if _, err := extendPreset(p.Name, nil); err != nil {
+			t.Errorf("preset %s: %v", p.Name, err)
+		}
+	}
+	for _, want := range []string{"changelog", "docs-site", "relaxed", "strict"} {
+		if _, ok := presets[want]; !ok {
+			t.Errorf("missing preset %s in %v", want, names)
+		}
+	}
+}
+
+// TestLoadPreset verifies that a preset chosen on the command line lies
+// beneath the project configuration.
+func TestLoadPreset(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	proj := filepath.Join(t.TempDir(), ProjectFile)
+	writeFile(t, proj, "version: 1\nseverity:\n  MD1000: warning\n")
+
+	cfg, err := LoadFile(Config{Extends: []string{"strict"}}, proj)
+	if err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	if cfg.Severity["MD1000"] != "warning" || cfg.Severity["MD1800"] != "error" {
+		t.Fatalf("unexpected severity: %v", cfg.Severity)
+	}
+	if cfg.Sources["severity.MD1800"] != "preset strict" {
+		t.Fatalf("unexpected source %q", cfg.Sources["severity.MD1800"])
+	}
+
+	if _, err := LoadFile(Config{Extends: []string{"nope"}}, proj); err == nil {
+		t.Fatal("expected error for unknown preset")
+	}
+}
@@ -0,0 +1,256 @@
+// Copyright 2024 The mdlint Authors
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DirectiveRule is the ID of the rule reporting unknown or unused suppression
+// directives. The engine reports it itself, so configuration may name it even
+// when it is not registered.
+const DirectiveRule = "MD0001"
+
+// ruleType describes a registered rule.
+type ruleType struct {
+	// name is the rule's human readable label.
+	name string
+	// defaults holds the default options, or nil for rules without options.
+	defaults any
+}
+
+// ruleTypes maps the IDs of registered rules to their descriptions.
+var (
+	ruleTypes   = map[string]ruleType{}
+	ruleTypesMu sync.RWMutex
+)
+
+// RegisterRule declares a rule, its human readable name and the default value
+// of its typed options struct, so that its rules section can be validated,
+// decoded and described by Schema. defaults is nil for rules without options.
+// Fields are named by their yaml tags and described by their desc and enum
+// tags. engine.Register calls it for every rule.
+func RegisterRule(id, name string, defaults any) {
+	ruleTypesMu.Lock()
+	defer ruleTypesMu.Unlock()
+	ruleTypes[id] = ruleType{name: name, defaults: defaults}
+}
+
+// ruleDefaults returns the default options registered for id.
+func ruleDefaults(id string) (defaults any, ok bool) {
+	ruleTypesMu.RLock()
+	defer ruleTypesMu.RUnlock()
+	r, ok := ruleTypes[id]
+	return r.defaults, ok
+}
+
+// RuleOptions holds the options of one rule as written in the rules section
+// of every configuration layer, in merge order. Later layers override the
+// fields they set.
+type RuleOptions []*yaml.Node
+
+// UnmarshalYAML keeps the options undecoded until the rule's type is known.
+func (o *RuleOptions) UnmarshalYAML(n *yaml.Node) error {
+	*o = RuleOptions{n}
+	return nil
+}
+
+// MarshalYAML renders the options of all layers merged into one mapping the
+// way they are decoded: later scalars and sequences replace earlier ones
+// while mappings are merged.
+func (o RuleOptions) MarshalYAML() (any, error) {
+	merged := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, n := range o {
+		if n.Kind == yaml.MappingNode {
+			mergeNode(merged, n)
+		}
+	}
+	return merged, nil
+}
+
+// mergeNode merges a copy of the mapping src into the mapping dst.
+func mergeNode(dst, src *yaml.Node) {
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i], src.Content[i+1]
+		prev := lookup(dst, key.Value)
+		switch {
+		case prev != nil && prev.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode:
+			mergeNode(prev, value)
+		case prev != nil:
+			*prev = *copyNode(value)
+		default:
+			dst.Content = append(dst.Content, copyNode(key), copyNode(value))
+		}
+	}
+}
+
+// copyNode returns a deep copy of n.
+func copyNode(n *yaml.Node) *yaml.Node {
+	c := *n
+	c.Content = make([]*yaml.Node, len(n.Content))
+	for i, child := range n.Content {
+		c.Content[i] = copyNode(child)
+	}
+	return &c
+}
+
+// RuleOptions returns opts, a rule's default options, with the rules section
+// of c for id decoded on top. Fields set by a layer replace those of opts,
+// except that maps gain the layer's entries. opts must not share maps with
+// values that outlive the call.
+func (c Config) RuleOptions(id string, opts any) (any, error) {
+	layers := c.Rules[id]
+	if len(layers) == 0 || opts == nil {
+		return opts, nil
+	}
+	v := reflect.New(reflect.TypeOf(opts))
+	v.Elem().Set(reflect.ValueOf(opts))
+	for _, n := range layers {
+		if err := decodeOptions(id, n, v.Interface()); err != nil {
+			return nil, err
+		}
+	}
+	return v.Elem().Interface(), nil
+}
+
+// validateRules checks every rules entry against the registered options
+// types.
+func validateRules(rules map[string]RuleOptions) error {
+	for _, id := range sortedKeys(rules) {
+		defaults, ok := ruleDefaults(id)
+		if !ok {
+			return keyErrorf([]string{"rules", id}, "rules: unknown rule %q%s", id, didYouMean(id, ruleIDs()))
+		}
+		for _, n := range rules[id] {
+			if defaults == nil && !isNull(n) {
+				return keyErrorf([]string{"rules", id}, "rules.%s: rule has no options", id)
+			}
+			if defaults == nil {
+				continue
+			}
+			// Decode into a zero value: the registered defaults may hold
+			// maps that must not be modified.
+			v := reflect.New(reflect.TypeOf(defaults))
+			if err := decodeOptions(id, n, v.Interface()); err != nil {
+				return err
+			}
+			if err := checkOptionEnums(id, v.Elem()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ruleIDs returns the IDs of the registered rules.
+func ruleIDs() []string {
+	ruleTypesMu.RLock()
+	defer ruleTypesMu.RUnlock()
+	return sortedKeys(ruleTypes)
+}
+
+// knownRuleIDs returns the IDs that severity and ignored settings may name:
+// the registered rules and DirectiveRule.
+func knownRuleIDs() []string {
+	ids := ruleIDs()
+	if !contains(ids, DirectiveRule) {
+		ids = append(ids, DirectiveRule)
+	}
+	return ids
+}
+
+// decodeOptions decodes the options node n of rule id into out, a pointer to
+// the rule's options struct, rejecting unknown fields.
+func decodeOptions(id string, n *yaml.Node, out any) error {
+	if isNull(n) {
+		return nil
+	}
+	key := []string{"rules", id}
+	if n.Kind != yaml.MappingNode {
+		return keyErrorf(key, "rules.%s: options must be a mapping", id)
+	}
+	known := optionNames(reflect.TypeOf(out).Elem())
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		name := n.Content[i].Value
+		if !contains(known, name) {
+			hint := didYouMean(name, known)
+			if hint == "" {
+				hint = fmt.Sprintf(" (known options: %s)", strings.Join(known, ", "))
+			}
+			return keyErrorf(append(key, name), "rules.%s: unknown option %q%s", id, name, hint)
+		}
+	}
+	// The node keeps its position in the configuration file, so type errors
+	// report the line of the offending value.
+	if err := n.Decode(out); err != nil {
+		return within(yamlError(err), fmt.Sprintf("rules.%s: ", id), key...)
+	}
+	return nil
+}
+
+// checkOptionEnums reports string fields of the options struct v of rule id
+// holding a value not listed by their enum tag.
+func checkOptionEnums(id string, v reflect.Value) error {
+	t := v.Type()
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		enum := f.Tag.Get("enum")
+		if enum == "" || f.Type.Kind() != reflect.String {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if err := checkEnum([]string{name}, name, v.Field(i).String(), strings.Split(enum, ",")); err != nil {
+			return within(err, fmt.Sprintf("rules.%s: ", id), "rules", id)
+		}
+	}
+	return nil
+}
+
+// optionNames returns the sorted YAML names of the fields of the struct t.
+func optionNames(t reflect.Type) []string {
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// isNull reports whether n is absent or an explicit null.
+func isNull(n *yaml.Node) bool {
+	return n == nil || (n.Kind == yaml.ScalarNode && n.Tag == "!!null")
+}
+
+// contains reports whether list holds s.
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+// Copyright 2025 Sam Caldwell
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testOptions is the options type of the rules registered by these tests.
+type testOptions struct {
+	Limit  int               `yaml:"limit" desc:"Maximum count."`
+	Strict bool              `yaml:"strict"`
+	Mode   string            `yaml:"mode" enum:"fast,slow"`
+	Words  []string          `yaml:"words"`
+	Terms  map[string]string `yaml:"terms"`
+}
+
+func init() {
+	RegisterRule("TEST1", "test one", testOptions{Limit: 80})
+	RegisterRule("TEST2", "test two", nil)
+	// Stand-ins for the built-in rules named by presets and tests, which
+	// register themselves from packages importing this one.
+	for _, id := range []string{"MD1000", "MD1100", "MD1101", "MD1400", "MD1500", "MD1800"} {
+		RegisterRule(id, "", nil)
+	}
+}
+
+// TestRuleOptions verifies that rules entries of every layer are decoded on
+// top of the defaults in order.
+func TestRuleOptions(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".git", "HEAD"), "")
+	writeFile(t, filepath.Join(dir, ProjectFile), `version: 1
+rules:
+  TEST1:
+    limit: 100
+    words: [a, b]
+    terms: {x: y}
+paths:
+  "docs/**":
+    rules:
+      TEST1:
+        strict: true
+`)
+	cfg, err := Load(Config{}, dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	cfg, err = cfg.Resolve(filepath.Join(dir, "docs", "a.md"))
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	cfg = cfg.WithFile(FileConfig{Rules: map[string]RuleOptions{"TEST1": mustOptions(t, "words: [c]\nterms: {z: w}\n")}})
+
+	defaults := testOptions{Limit: 80, Terms: map[string]string{"d": "e"}}
+	got, err := cfg.RuleOptions("TEST1", defaults)
+	if err != nil {
+		t.Fatalf("RuleOptions: %v", err)
+	}
+	want := testOptions{Limit: 100, Strict: true, Words: []string{"c"}, Terms: map[string]string{"d": "e", "x": "y", "z": "w"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v want %+v", got, want)
+	}
+	if cfg.Sources["rules.TEST1.limit"] == "" || cfg.Sources["rules.TEST1.strict"] != `paths "docs/**"` {
+		t.Fatalf("unexpected sources: %v", cfg.Sources)
+	}
+
+	out, err := cfg.Marshal("yaml")
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if !strings.Contains(string(out), "    words:\n      - c # front matter\n") {
+		t.Fatalf("unexpected rendering:\n%s", out)
+	}
+
+	if got, err := (Config{}).RuleOptions("TEST1", defaults); err != nil || !reflect.DeepEqual(got, defaults) {
+		t.Fatalf("expected defaults, got %+v, %v", got, err)
+	}
+}
+
+// TestRuleOptionsErrors verifies that rules entries are validated strictly
+// with errors naming the rule and the field.
+func TestRuleOptionsErrors(t *testing.T) {
+	for src, msg := range map[string]string{
+		"rules:\n  TEST9:\n    limit: 1\n":                    `rules: unknown rule "TEST9" (did you mean "TEST1"?)`,
+		"rules:\n  TEST1:\n    limt: 1\n":                     `rules.TEST1: unknown option "limt" (did you mean "limit"?)`,
+		"rules:\n  TEST1:\n    xyzzy: 1\n":                    `rules.TEST1: unknown option "xyzzy" (known options: limit, mode, strict, terms, words)`,
+		"rules:\n  TEST1:\n    limit: many\n":                 "line 4: rules.TEST1: cannot unmarshal !!str `many` into int",
+		"rules:\n  TEST1:\n    mode: fats\n":                  `rules.TEST1: invalid mode "fats" (did you mean "fast"?)`,
+		"rules:\n  TEST1: [limit]\n":                          `rules.TEST1: options must be a mapping`,
+		"rules:\n  TEST2:\n    limit: 1\n":                    `rules.TEST2: rule has no options`,
+		"paths:\n  docs/:\n    rules:\n      TEST1: {x: 1}\n": `paths "docs/": rules.TEST1: unknown option "x"`,
+	} {
+		cfg, err := parseYAML([]byte("version: 1\n" + src))
+		if err != nil {
+			t.Fatalf("%q: parse: %v", src, err)
+		}
+		if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), msg) {
+			t.Errorf("%q: expected error containing %q, got %v", src, msg, err)
+		}
+	}
+	if _, _, err := ParseFrontMatter([]byte("mdlint:\n  rules:\n    TEST1:\n      nope: 1\n")); err == nil ||
+		!strings.Contains(err.Error(), `unknown option "nope"`) {
+		t.Fatalf("expected front matter error, got %v", err)
+	}
+	cfg, err := parseYAML([]byte("version: 1\nrules:\n  TEST2:\n"))
+	if err != nil || cfg.Validate() != nil {
+		t.Fatalf("empty options of a rule without options must be accepted: %v", err)
+	}
+}
+
+// mustOptions decodes a rules entry.
+func mustOptions(t *testing.T, src string) RuleOptions {
+	t.Helper()
+	cfg, err := parseYAML([]byte("rules:\n  X:\n" + indent(src)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg.Rules["X"]
+}
+
+func indent(s string) string {
+	return "    " + strings.ReplaceAll(strings.TrimSuffix(s, "\n"), "\n", "\n    ") + "\n"
+}
@@ -0,0 +1,147 @@
+// Copyright 2024 The mdlint Authors
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// SchemaURI identifies the JSON Schema dialect of Schema.
+const SchemaURI = "https://json-schema.org/draft/2020-12/schema"
+
+// severities lists the valid severities from least to most severe.
+var severities = []string{"suggestion", "warning", "error"}
+
+var (
+	severityType = reflect.TypeOf(Severity(""))
+	rulesType    = reflect.TypeOf(map[string]RuleOptions(nil))
+)
+
+// Schema returns a JSON Schema describing configuration files. It is derived
+// from the yaml tags of Config and the options types of every registered rule:
+// the desc tag of a field becomes its description and the enum tag, a comma
+// separated list, its allowed values. Non-zero defaults are included.
+func Schema() ([]byte, error) {
+	s := schemaFor(reflect.TypeOf(Config{}), reflect.ValueOf(DefaultConfig()))
+	// Validate accepts only the current version; older ones need migrating.
+	s["properties"].(map[string]any)["version"].(map[string]any)["enum"] = []int{CurrentVersion()}
+	s["$schema"] = SchemaURI
+	s["title"] = "mdlint configuration"
+	s["$defs"] = map[string]any{"rules": rulesSchema()}
+	out, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+// rulesSchema describes the rules section: one property per registered rule,
+// holding its options or null for rules without options.
+func rulesSchema() map[string]any {
+	ruleTypesMu.RLock()
+	defer ruleTypesMu.RUnlock()
+	props := make(map[string]any, len(ruleTypes))
+	for id, r := range ruleTypes {
+		var s map[string]any
+		if r.defaults == nil {
+			s = map[string]any{"type": "null"}
+		} else {
+			s = schemaFor(reflect.TypeOf(r.defaults), reflect.ValueOf(r.defaults))
+			// An empty entry keeps the defaults.
+			s["type"] = []string{"object", "null"}
+		}
+		if r.name != "" {
+			s["description"] = id + ": " + r.name
+		}
+		props[id] = s
+	}
+	return map[string]any{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": false,
+	}
+}
+
+// schemaFor returns the schema of values of type t. def, when valid, holds
+// the default value whose non-zero fields are recorded.
+func schemaFor(t reflect.Type, def reflect.Value) map[string]any {
+	switch {
+	case t == severityType:
+		return map[string]any{"type": "string", "enum": severities}
+	case t == rulesType:
+		return map[string]any{"$ref": "#/$defs/rules"}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		if def.IsValid() && !def.IsNil() {
+			def = def.Elem()
+		} else {
+			def = reflect.Value{}
+		}
+		return schemaFor(t.Elem(), def)
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": schemaFor(t.Elem(), reflect.Value{})}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaFor(t.Elem(), reflect.Value{})}
+	case reflect.Struct:
+		props := map[string]any{}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+			if !f.IsExported() || name == "-" {
+				continue
+			}
+			if name == "" {
+				name = strings.ToLower(f.Name)
+			}
+			var fv reflect.Value
+			if def.IsValid() {
+				fv = def.Field(i)
+			}
+			s := schemaFor(f.Type, fv)
+			if desc := f.Tag.Get("desc"); desc != "" {
+				s["description"] = desc
+			}
+			if enum := f.Tag.Get("enum"); enum != "" {
+				s["enum"] = enumValues(f.Type, enum)
+			}
+			if fv.IsValid() && !fv.IsZero() && fv.Kind() != reflect.Struct {
+				s["default"] = fv.Interface()
+			}
+			props[name] = s
+		}
+		return map[string]any{
+			"type":                 "object",
+			"properties":           props,
+			"additionalProperties": false,
+		}
+	}
+	return map[string]any{}
+}
+
+// enumValues splits the enum tag list, converting the values to integers for
+// integer fields.
+func enumValues(t reflect.Type, list string) []any {
+	var values []any
+	for _, v := range strings.Split(list, ",") {
+		if n, err := strconv.Atoi(v); err == nil && t.Kind() != reflect.String {
+			values = append(values, n)
+			continue
+		}
+		values = append(values, v)
+	}
+	return values
+}
@@ -0,0 +1,103 @@
+// Copyright 2025 Sam Caldwell
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestSchema verifies that the schema describes the configuration structs and
+// the options of registered rules.
+func TestSchema(t *testing.T) {
+	data, err := Schema()
+	if err != nil {
+		t.Fatalf("Schema: %v", err)
+	}
+	var s map[string]any
+	if err := json.Unmarshal(data, &s); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if s["$schema"] != SchemaURI || s["additionalProperties"] != false {
+		t.Fatalf("unexpected root: %v", s)
+	}
+	get := func(path string) any {
+		var v any = s
+		for _, k := range strings.Split(path, ".") {
+			v = v.(map[string]any)[k]
+		}
+		return v
+	}
+	for path, want := range map[string]any{
+		"properties.version.enum":                                                 []any{float64(CurrentVersion())},
+		"properties.version.default":                                              float64(CurrentVersion()),
+		"properties.failure_threshold.enum":                                       []any{"suggestion", "warning", "error"},
+		"properties.failure_threshold.default":                                    "warning",
+		"properties.heading.properties.style.enum":                                []any{"atx", "setext", "consistent"},
+		"properties.output.properties.color.enum":                                 []any{"auto", "always", "never"},
+		"properties.severity.additionalProperties.enum":                           []any{"suggestion", "warning", "error"},
+		"properties.rules.$ref":                                                   "#/$defs/rules",
+		"properties.paths.additionalProperties.properties.rules.$ref":             "#/$defs/rules",
+		"properties.extends.items.type":                                           "string",
+		"$defs.rules.additionalProperties":                                        false,
+		"$defs.rules.properties.TEST1.description":                                "TEST1: test one",
+		"$defs.rules.properties.TEST1.properties.limit.type":                      "integer",
+		"$defs.rules.properties.TEST1.properties.limit.default":                   float64(80),
+		"$defs.rules.properties.TEST1.properties.limit.description":               "Maximum count.",
+		"$defs.rules.properties.TEST1.properties.mode.enum":                       []any{"fast", "slow"},
+		"$defs.rules.properties.TEST1.properties.terms.additionalProperties.type": "string",
+		"$defs.rules.properties.TEST2.type":                                       "null",
+	} {
+		if got := get(path); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %v want %v", path, got, want)
+		}
+	}
+	if get("properties.spell.properties.lang.description") == nil {
+		t.Errorf("missing description of spell.lang")
+	}
+}
+
+// TestSchemaEnums verifies that Validate accepts exactly the values the
+// schema's enums list.
+func TestSchemaEnums(t *testing.T) {
+	for _, tc := range []struct {
+		field string
+		t     reflect.Type
+		set   func(*Config, string)
+	}{
+		{"style", reflect.TypeOf(HeadingConfig{}), func(c *Config, v string) { c.Heading.Style = v }},
+		{"format", reflect.TypeOf(OutputConfig{}), func(c *Config, v string) { c.Output.Format = v }},
+		{"color", reflect.TypeOf(OutputConfig{}), func(c *Config, v string) { c.Output.Color = v }},
+		{"failure_threshold", reflect.TypeOf(Config{}), func(c *Config, v string) { c.FailureThreshold = Severity(v) }},
+	} {
+		values := severities
+		if f, ok := fieldByYAML(tc.t, tc.field); ok && f.Tag.Get("enum") != "" {
+			values = strings.Split(f.Tag.Get("enum"), ",")
+		}
+		for _, v := range values {
+			cfg := DefaultConfig()
+			tc.set(&cfg, v)
+			if err := cfg.Validate(); err != nil {
+				t.Errorf("%s %q rejected: %v", tc.field, v, err)
+			}
+		}
+		cfg := DefaultConfig()
+		tc.set(&cfg, "bogus")
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("%s: bogus value accepted", tc.field)
+		}
+	}
+}
+
+// fieldByYAML returns the field of the struct t named name by its yaml tag.
+func fieldByYAML(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		if n, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ","); n == name {
+			return t.Field(i), true
+		}
+	}
+	return reflect.StructField{}, false
+}
@@ -0,0 +1,137 @@
+// Copyright 2024 The mdlint Authors
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Source layers recorded in Config.Sources besides configuration files and
+// path overrides.
+const (
+	// SourceDefault marks built-in default values.
+	SourceDefault = "default"
+	// SourceCLI marks values set by command-line flags.
+	SourceCLI = "command line"
+	// SourceFrontMatter marks values set by a document's front matter.
+	SourceFrontMatter = "front matter"
+	// SourceEnv marks values set by environment variables. The name of the
+	// variable follows it, as in "environment MDLINT_OUTPUT_FORMAT".
+	SourceEnv = "environment"
+)
+
+// record notes source as the origin of every value set in layer. Values are
+// keyed by their dotted YAML path, with list items keyed by value, e.g.
+// "output.format", "severity.MD1000" or "ignored[MD1500]". Nothing is
+// recorded unless c tracks sources.
+func (c *Config) record(layer Config, source string) {
+	if c.Sources == nil {
+		return
+	}
+	var n yaml.Node
+	if err := n.Encode(layer); err != nil {
+		return
+	}
+	walk("", &n, func(key string, v *yaml.Node) {
+		if !isZero(v) {
+			c.Sources[key] = source
+		}
+	})
+}
+
+// walk calls fn for every scalar of n and every item of its sequences, with
+// the item's key as described by record.
+func walk(prefix string, n *yaml.Node, fn func(key string, v *yaml.Node)) {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		for _, c := range n.Content {
+			walk(prefix, c, fn)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key := n.Content[i].Value
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			walk(key, n.Content[i+1], fn)
+		}
+	case yaml.SequenceNode:
+		for _, item := range n.Content {
+			fn(prefix+"["+item.Value+"]", item)
+		}
+	case yaml.ScalarNode:
+		fn(prefix, n)
+	}
+}
+
+// blockStyle renders the collections of n in block style, recursively.
+func blockStyle(n *yaml.Node) {
+	if n.Kind == yaml.MappingNode || n.Kind == yaml.SequenceNode {
+		n.Style &^= yaml.FlowStyle
+	}
+	for _, c := range n.Content {
+		blockStyle(c)
+	}
+}
+
+// isZero reports whether v encodes an unset value.
+func isZero(v *yaml.Node) bool {
+	switch v.Tag {
+	case "!!null":
+		return true
+	case "!!str":
+		return v.Value == ""
+	case "!!int":
+		return v.Value == "0"
+	}
+	return false
+}
+
+// Marshal renders the configuration as "yaml" or "json". YAML values carry a
+// comment naming the layer they came from when c tracks sources; JSON output
+// holds the configuration and the sources side by side.
+func (c Config) Marshal(format string) ([]byte, error) {
+	var n yaml.Node
+	if err := n.Encode(c); err != nil {
+		return nil, err
+	}
+	switch format {
+	case "", "yaml":
+		// Comments cannot follow the items of flow collections.
+		blockStyle(&n)
+		walk("", &n, func(key string, v *yaml.Node) {
+			if src, ok := c.Sources[key]; ok {
+				v.LineComment = src
+			}
+		})
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(&n); err != nil {
+			return nil, err
+		}
+		if err := enc.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case "json":
+		var v any
+		if err := n.Decode(&v); err != nil {
+			return nil, err
+		}
+		out, err := json.MarshalIndent(struct {
+			Config  any               `json:"config"`
+			Sources map[string]string `json:"sources"`
+		}{v, c.Sources}, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(out, '\n'), nil
+	}
+	return nil, fmt.Errorf("unsupported format %q", format)
+}
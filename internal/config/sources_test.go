@@ -0,0 +1,81 @@
+// Copyright 2025 Sam Caldwell
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestSources verifies that every layer records the values it sets.
+func TestSources(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := t.TempDir()
+	proj := filepath.Join(dir, ".mdlintrc.yaml")
+	src := "version: 1\nignored: [MD1500]\npaths:\n  \"docs/**\":\n    severity:\n      MD1000: error\n"
+	if err := os.WriteFile(proj, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadFile(Config{Output: OutputConfig{Format: "text"}}, proj)
+	if err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	cfg = cfg.ForFile(filepath.Join(dir, "docs", "a.md")).WithFile(FileConfig{Ignored: []string{"MD1800"}})
+	for key, want := range map[string]string{
+		"version":                       proj,
+		"ignored[MD1500]":               proj,
+		"ignored[MD1800]":               SourceFrontMatter,
+		"severity.MD1000":               `paths "docs/**"`,
+		"paths.docs/**.severity.MD1000": proj,
+		"output.format":                 SourceCLI,
+		"output.color":                  SourceDefault,
+	} {
+		if got := cfg.Sources[key]; got != want {
+			t.Errorf("source of %s = %q, want %q", key, got, want)
+		}
+	}
+	if _, ok := cfg.Sources["spell.lang"]; ok {
+		t.Error("unset value has a source")
+	}
+}
+
+// TestMarshal verifies the annotated YAML and the JSON renderings.
+func TestMarshal(t *testing.T) {
+	cfg := Config{
+		Version: 1,
+		Ignored: []string{"MD1500"},
+		Sources: map[string]string{"version": SourceDefault, "ignored[MD1500]": "project.yaml"},
+	}
+	out, err := cfg.Marshal("yaml")
+	if err != nil {
+		t.Fatalf("Marshal yaml: %v", err)
+	}
+	for _, want := range []string{"version: 1 # default\n", "  - MD1500 # project.yaml\n"} {
+		if !strings.Contains(string(out), want) {
+			t.Fatalf("expected %q in:\n%s", want, out)
+		}
+	}
+
+	out, err = cfg.Marshal("json")
+	if err != nil {
+		t.Fatalf("Marshal json: %v", err)
+	}
+	var doc struct {
+		Config  map[string]any    `json:"config"`
+		Sources map[string]string `json:"sources"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("invalid json: %v\n%s", err, out)
+	}
+	if doc.Config["version"] != float64(1) || doc.Sources["version"] != SourceDefault {
+		t.Fatalf("unexpected json: %s", out)
+	}
+
+	if _, err := cfg.Marshal("toml"); err == nil {
+		t.Fatal("expected error for unsupported format")
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright 2024 The mdlint Authors
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pelletier/go-toml/v2/unstable"
+	"gopkg.in/yaml.v3"
+)
+
+// parseTOML converts a TOML document into the equivalent YAML mapping so that
+// TOML configuration files are decoded, checked and located like YAML ones.
+// The document is validated by go-toml first; its syntax tree then gives the
+// position of every key and value. Dates and times become strings. The syntax
+// tree comes from go-toml's unstable package, whose API may change between
+// releases, so go.mod pins the exact version.
+func parseTOML(data []byte) (*yaml.Node, error) {
+	err := checkTOML(data)
+	var de *toml.DecodeError
+	if errors.As(err, &de) {
+		line, col := de.Position()
+		return nil, &Error{Line: line, Column: col, Err: tomlMessage(err)}
+	}
+
+	b := tomlBuilder{defined: map[*yaml.Node]bool{}}
+	b.p.Reset(data)
+	root := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: 1, Column: 1}
+	table := root
+	for table != nil && b.p.NextExpression() {
+		e := b.p.Expression()
+		switch e.Kind {
+		case unstable.Table, unstable.ArrayTable:
+			table = b.table(root, e.Key(), e.Kind == unstable.ArrayTable)
+		case unstable.KeyValue:
+			b.keyValue(table, e)
+		}
+		if b.conflict != nil {
+			break
+		}
+	}
+	if err != nil {
+		// go-toml reports conflicting definitions, such as a table defined
+		// twice, without a position; the first conflict found gives it.
+		e := &Error{Err: tomlMessage(err)}
+		if b.conflict != nil {
+			e.Line, e.Column = b.conflict.Line, b.conflict.Column
+		}
+		return nil, e
+	}
+	if err := b.p.Error(); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// checkTOML reports whether data is a valid TOML document.
+func checkTOML(data []byte) error {
+	var v map[string]any
+	return toml.Unmarshal(data, &v)
+}
+
+// tomlMessage returns the message of the go-toml error err.
+func tomlMessage(err error) error {
+	return errors.New(strings.TrimPrefix(err.Error(), "toml: "))
+}
+
+// tomlBuilder builds the YAML nodes of a TOML document from its syntax tree.
+type tomlBuilder struct {
+	p unstable.Parser
+	// defined holds the tables defined by a header or a dotted key, which
+	// may not be defined again.
+	defined map[*yaml.Node]bool
+	// conflict is the first key redefining a value or table, if any.
+	conflict *yaml.Node
+}
+
+// table returns the mapping of the table whose header has the given keys,
+// creating it and its parents as needed. Headers of arrays of tables append
+// a new mapping to the array; other keys naming an array of tables refer to
+// its last element. It returns nil when the header redefines a value or
+// table.
+func (b *tomlBuilder) table(root *yaml.Node, keys unstable.Iterator, array bool) *yaml.Node {
+	m := root
+	for keys.Next() {
+		k := keys.Node()
+		last := keys.IsLast()
+		child := lookup(m, string(k.Data))
+		if child == nil {
+			key := b.scalar(k, "!!str", string(k.Data), nil)
+			child = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: key.Line, Column: key.Column}
+			if last && array {
+				child = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Line: key.Line, Column: key.Column}
+			}
+			m.Content = append(m.Content, key, child)
+		} else if child.Kind == yaml.ScalarNode || child.Style == yaml.FlowStyle ||
+			(last && array != (child.Kind == yaml.SequenceNode)) || (last && !array && b.defined[child]) {
+			b.conflict = b.scalar(k, "!!str", string(k.Data), nil)
+			return nil
+		}
+		if last && !array {
+			b.defined[child] = true
+		}
+		if child.Kind == yaml.SequenceNode {
+			if last && array {
+				s := b.p.Shape(k.Raw)
+				child.Content = append(child.Content, &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: s.Start.Line, Column: s.Start.Column})
+			}
+			child = child.Content[len(child.Content)-1]
+		}
+		m = child
+	}
+	return m
+}
+
+// keyValue adds the possibly dotted key and value of the expression e to the
+// mapping m, recording a conflict when the key is already defined.
+func (b *tomlBuilder) keyValue(m *yaml.Node, e *unstable.Node) {
+	keys := e.Key()
+	for keys.Next() {
+		k := keys.Node()
+		child := lookup(m, string(k.Data))
+		if child != nil && (keys.IsLast() || child.Kind != yaml.MappingNode || child.Style == yaml.FlowStyle) {
+			b.conflict = b.scalar(k, "!!str", string(k.Data), nil)
+			return
+		}
+		if keys.IsLast() {
+			key := b.scalar(k, "!!str", string(k.Data), nil)
+			m.Content = append(m.Content, key, b.value(e.Value(), key))
+			return
+		}
+		if child == nil {
+			key := b.scalar(k, "!!str", string(k.Data), nil)
+			child = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: key.Line, Column: key.Column}
+			m.Content = append(m.Content, key, child)
+			b.defined[child] = true
+		}
+		m = child
+	}
+}
+
+// value returns the YAML node of the TOML value n. Values without a position
+// of their own, such as booleans and arrays, take the position of key.
+func (b *tomlBuilder) value(n *unstable.Node, key *yaml.Node) *yaml.Node {
+	switch n.Kind {
+	case unstable.Array:
+		seq := b.scalar(n, "!!seq", "", key)
+		seq.Kind, seq.Style = yaml.SequenceNode, yaml.FlowStyle
+		for it := n.Children(); it.Next(); {
+			seq.Content = append(seq.Content, b.value(it.Node(), key))
+		}
+		return seq
+	case unstable.InlineTable:
+		m := b.scalar(n, "!!map", "", key)
+		m.Kind, m.Style = yaml.MappingNode, yaml.FlowStyle
+		for it := n.Children(); it.Next(); {
+			b.keyValue(m, it.Node())
+		}
+		return m
+	case unstable.Bool:
+		return b.scalar(n, "!!bool", string(n.Data), key)
+	case unstable.Integer:
+		i, err := strconv.ParseInt(string(n.Data), 0, 64)
+		if err != nil {
+			// go-toml accepted the integer, so only its form is unknown.
+			return b.scalar(n, "!!str", string(n.Data), key)
+		}
+		return b.scalar(n, "!!int", strconv.FormatInt(i, 10), key)
+	case unstable.Float:
+		return b.scalar(n, "!!float", tomlFloat(string(n.Data)), key)
+	}
+	return b.scalar(n, "!!str", string(n.Data), key)
+}
+
+// scalar returns a scalar node positioned at n, or at key when n has no
+// position of its own.
+func (b *tomlBuilder) scalar(n *unstable.Node, tag, value string, key *yaml.Node) *yaml.Node {
+	out := &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value}
+	if n.Raw.Length > 0 {
+		s := b.p.Shape(n.Raw)
+		out.Line, out.Column = s.Start.Line, s.Start.Column
+	} else if key != nil {
+		out.Line, out.Column = key.Line, key.Column
+	}
+	return out
+}
+
+// tomlFloat returns the YAML form of the TOML float s.
+func tomlFloat(s string) string {
+	switch strings.TrimPrefix(s, "+") {
+	case "inf":
+		return ".inf"
+	case "-inf":
+		return "-.inf"
+	case "nan", "-nan":
+		return ".nan"
+	}
+	f, err := strconv.ParseFloat(strings.ReplaceAll(s, "_", ""), 64)
+	if err != nil || math.IsInf(f, 0) {
+		return s
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// lookup returns the value of key in the mapping m, or nil.
+func lookup(m *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
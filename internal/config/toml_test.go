@@ -0,0 +1,117 @@
+// Copyright 2025 Sam Caldwell
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestTOML verifies that a TOML configuration decodes like its YAML
+// equivalent.
+func TestTOML(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	src := `# project settings
+version = 1
+ignored = [
+  "MD1500", # preferred terms
+  'MD1400',
+]
+failure_threshold = "error"
+heading = { style = "atx", allow_mixed = true }
+
+[severity]
+MD1000 = "warning"
+
+[paths."docs/**"]
+ignored = ["MD1800"]
+severity.MD1000 = "error"
+
+[spell]
+add_words = ["GoLand", "it's"]
+`
+	path := filepath.Join(t.TempDir(), "mdlint.toml")
+	writeFile(t, path, src)
+	cfg, err := LoadFile(Config{}, path)
+	if err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	if !reflect.DeepEqual(cfg.Ignored, []string{"MD1500", "MD1400"}) || cfg.FailureThreshold != "error" {
+		t.Fatalf("unexpected configuration: %+v", cfg)
+	}
+	if cfg.Heading.Style != "atx" || !isTrue(cfg.Heading.AllowMixed) || cfg.Severity["MD1000"] != "warning" {
+		t.Fatalf("unexpected configuration: %+v", cfg)
+	}
+	pc := cfg.Paths["docs/**"]
+	if !reflect.DeepEqual(pc.Ignored, []string{"MD1800"}) || pc.Severity["MD1000"] != "error" {
+		t.Fatalf("unexpected paths: %+v", cfg.Paths)
+	}
+	if !reflect.DeepEqual(cfg.Spell.AddWords, []string{"GoLand", "it's"}) {
+		t.Fatalf("unexpected words: %v", cfg.Spell.AddWords)
+	}
+}
+
+// TestTOMLValues verifies that TOML forms without a YAML counterpart in the
+// configuration schema are still converted.
+func TestTOMLValues(t *testing.T) {
+	src := `version = 0x1
+title = "a\bb\fc\ed"
+body = """
+multi
+line"""
+ratio = 1_000.5
+when = 1979-05-27T07:32:00Z
+[[items]]
+name = "one"
+[[items]]
+name = "two"
+`
+	doc, err := parseTOML([]byte(src))
+	if err != nil {
+		t.Fatalf("parseTOML: %v", err)
+	}
+	var got map[string]any
+	if err := doc.Decode(&got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	want := map[string]any{
+		"version": 1,
+		"title":   "a\bb\fc\x1bd",
+		"body":    "multi\nline",
+		"ratio":   1000.5,
+		"when":    "1979-05-27T07:32:00Z",
+		"items":   []any{map[string]any{"name": "one"}, map[string]any{"name": "two"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v want %#v", got, want)
+	}
+	if n := find(doc, []string{"items"}); n == nil || n.Line != 8 || n.Column != 3 {
+		t.Fatalf("unexpected position of items: %+v", n)
+	}
+}
+
+// TestTOMLErrors reports invalid TOML with its position.
+func TestTOMLErrors(t *testing.T) {
+	for src, msg := range map[string]string{
+		"version = 1\nversion = 1\n":    `line 2 column 1: `,
+		"[severity]\n[severity]\n":      `line 2 column 2: `,
+		"a.b = 1\n[a]\n":                `line 2 column 2: `,
+		"a = 1\n[a]\n":                  `line 2 column 2: `,
+		"a = []\n[a.b]\n":               `line 2 column 2: `,
+		"a = [1]\n[[a]]\n":              `line 2 column 3: `,
+		"[[a]]\n[a]\n":                  `line 2 column 2: `,
+		"[a]\nb.c = 1\n[a.b]\n":         `line 3 column 4: `,
+		"a.b = 1\na.b.c = 2\n":          `line 2 column 3: `,
+		"ignored = [\"MD1000\"\n":       `line 2 column 1: `,
+		"version = 1 2\n":               `line 1 column 13: `,
+		"name = \"bad \\q escape\"\n":   `line 1 column `,
+		"heading = { style = \"atx\"\n": `line 1 column `,
+	} {
+		if _, err := parseTOML([]byte(src)); err == nil || !strings.Contains(err.Error(), msg) {
+			t.Errorf("%q: expected error containing %q, got %v", src, msg, err)
+		}
+	}
+}
@@ -3,44 +3,75 @@
 package engine
 
 import (
-	"bufio"
+	"context"
+	"errors"
 	"os"
-	"strings"
 
+	"github.com/asymmetric-effort/mdlint/internal/config"
 	"github.com/asymmetric-effort/mdlint/internal/findings"
 )
 
+// markdownPatterns selects the files linted when a directory is walked.
+var markdownPatterns = []string{"*.md", "*.markdown"}
+
 // Engine executes lint rules.
-type Engine struct{}
+type Engine struct {
+	// Config is the resolved configuration applied to every file.
+	Config config.Config
+	// Workers bounds the number of files processed concurrently. If zero,
+	// runtime.NumCPU is used.
+	Workers int
+}
+
+// FileError reports a file that could not be linted because the settings in
+// its front matter are invalid. The other files are still linted: Run returns
+// their findings together with the FileErrors joined into one error.
+type FileError struct {
+	// Path is the file that was skipped.
+	Path string
+	// Err describes the problem, positioned within the file.
+	Err error
+}
+
+// Error returns the message of Err, which names the file.
+func (e *FileError) Error() string { return e.Err.Error() }
+
+// Unwrap returns the underlying error.
+func (e *FileError) Unwrap() error { return e.Err }
 
-// Run processes files and returns findings.
-func (Engine) Run(paths []string) ([]findings.Finding, error) {
+// Run processes files and returns findings. Directories are walked
+// recursively for Markdown files while files named explicitly are always
+// linted. Findings are returned in a deterministic order. Files with invalid
+// front matter are reported by a *FileError in the returned error, alongside
+// the findings of the other files.
+func (e Engine) Run(paths []string) ([]findings.Finding, error) {
 	var result []findings.Finding
+	var skipped []error
 	for _, p := range paths {
-		file, err := os.Open(p)
+		info, err := os.Stat(p)
 		if err != nil {
 			return nil, err
 		}
-		scanner := bufio.NewScanner(file)
-		line := 1
-		for scanner.Scan() {
-			text := scanner.Text()
-			if idx := strings.Index(text, "TODO"); idx >= 0 {
-				result = append(result, findings.Finding{
-					Rule:    "MD9000",
-					Message: "TODO found",
-					File:    p,
-					Line:    line,
-					Column:  idx + 1,
-				})
-			}
-			line++
+		cfg := Config{Workers: e.Workers, Lint: e.Config}
+		if info.IsDir() {
+			cfg.Include = markdownPatterns
 		}
-		if err := scanner.Err(); err != nil {
-			_ = file.Close()
-			return nil, err
+		fs, err := Run(context.Background(), p, cfg)
+		if err != nil {
+			if !IsFileError(err) {
+				return nil, err
+			}
+			skipped = append(skipped, err)
 		}
-		_ = file.Close()
+		result = append(result, fs...)
 	}
-	return result, nil
+	sortFindings(result)
+	return result, errors.Join(skipped...)
+}
+
+// IsFileError reports whether err only reports files that were skipped, so
+// that the findings returned with it are complete for the other files.
+func IsFileError(err error) bool {
+	var fe *FileError
+	return errors.As(err, &fe)
 }
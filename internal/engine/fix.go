@@ -0,0 +1,152 @@
+// Copyright 2024 MdLint Authors
+
+package engine
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/asymmetric-effort/mdlint/internal/config"
+	"github.com/asymmetric-effort/mdlint/internal/findings"
+	"github.com/asymmetric-effort/mdlint/internal/fix"
+)
+
+// maxFixPasses bounds how often a file is re-linted and fixed. Each pass
+// applies every non-overlapping edit, so well-behaved rules settle within a
+// few passes; the bound guards against rules whose fixes never converge.
+const maxFixPasses = 10
+
+// FixResult summarizes an Engine.Fix or Engine.PlanFixes run.
+type FixResult struct {
+	// Fixed lists the findings resolved by applied edits, in the order they
+	// were fixed. Their positions refer to the file contents at the time of
+	// the fix.
+	Fixed []findings.Finding
+	// Remaining lists the findings reported after all fixes were applied.
+	Remaining []findings.Finding
+	// Changes lists the files whose contents were, or would be, changed.
+	Changes []FileFix
+}
+
+// FileFix describes the fixes made to one file.
+type FileFix struct {
+	// Path is the file that was fixed.
+	Path string
+	// Before and After hold the file contents before and after fixing.
+	Before, After []byte
+	// Fixed lists the findings resolved in this file.
+	Fixed []findings.Finding
+}
+
+// Fix lints paths like Run and applies the suggestions of fixable rules,
+// rewriting each changed file atomically. The findings left after fixing are
+// returned in Remaining. Like Run, it reports skipped files by a *FileError
+// after fixing the others.
+func (e Engine) Fix(paths []string) (FixResult, error) {
+	res, err := e.PlanFixes(paths)
+	if err != nil && !IsFileError(err) {
+		return res, err
+	}
+	for _, c := range res.Changes {
+		if err := fix.WriteFile(c.Path, c.After); err != nil {
+			return res, fmt.Errorf("%s: %w", c.Path, err)
+		}
+	}
+	return res, err
+}
+
+// PlanFixes computes the fixes Fix would make without writing any file. Each
+// file is fixed in memory and re-linted until no further edits apply.
+func (e Engine) PlanFixes(paths []string) (FixResult, error) {
+	var res FixResult
+	found, skipped := e.Run(paths)
+	if skipped != nil && !IsFileError(skipped) {
+		return res, skipped
+	}
+	var pending []findings.Finding
+	files := fixableFiles(found)
+	for _, f := range found {
+		if !contains(files, f.File) {
+			pending = append(pending, f)
+		}
+	}
+	for _, path := range files {
+		c, remaining, err := fixFile(path, e.Config)
+		if err != nil {
+			return res, err
+		}
+		if len(c.Fixed) > 0 {
+			res.Fixed = append(res.Fixed, c.Fixed...)
+			res.Changes = append(res.Changes, c)
+		}
+		pending = append(pending, remaining...)
+	}
+	sortFindings(pending)
+	res.Remaining = pending
+	return res, skipped
+}
+
+// fixFile repeatedly lints the file at path and applies fixable suggestions
+// in memory until the contents stop changing. It returns the change made and
+// the findings remaining in the fixed contents.
+func fixFile(path string, lint config.Config) (FileFix, []findings.Finding, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return FileFix{}, nil, err
+	}
+	c := FileFix{Path: path, Before: content, After: content}
+	for pass := 0; ; pass++ {
+		found, err := lintSource(path, c.After, lint)
+		if err != nil {
+			return FileFix{}, nil, err
+		}
+		if pass == maxFixPasses {
+			return c, found, nil
+		}
+		out, applied := fix.Apply(c.After, fixable(found))
+		if len(applied) == 0 {
+			return c, found, nil
+		}
+		c.After = out
+		c.Fixed = append(c.Fixed, applied...)
+	}
+}
+
+// fixable returns the findings of fs reported by fixable rules.
+func fixable(fs []findings.Finding) []findings.Finding {
+	var result []findings.Finding
+	for _, f := range fs {
+		if isFixable(f) {
+			result = append(result, f)
+		}
+	}
+	return result
+}
+
+// isFixable reports whether f carries a suggestion from a fixable rule.
+func isFixable(f findings.Finding) bool {
+	if len(f.Suggestions) == 0 {
+		return false
+	}
+	r, ok := GetRule(f.Rule)
+	if !ok {
+		return false
+	}
+	fx, ok := r.(Fixable)
+	return ok && fx.Fixable()
+}
+
+// fixableFiles returns the sorted, distinct files with fixable findings.
+func fixableFiles(fs []findings.Finding) []string {
+	seen := map[string]bool{}
+	var files []string
+	for _, f := range fs {
+		if isFixable(f) && !seen[f.File] {
+			seen[f.File] = true
+			files = append(files, f.File)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
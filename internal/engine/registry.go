@@ -7,6 +7,8 @@ package engine
 import (
 	"sort"
 	"sync"
+
+	"github.com/asymmetric-effort/mdlint/internal/config"
 )
 
 // registry holds all registered rules keyed by their identifier.
@@ -17,7 +19,9 @@ var (
 
 // Register adds the given rule to the global registry. It panics if a rule with
 // the same ID has already been registered. Registration is typically performed
-// in the rule's init function.
+// in the rule's init function. The options type of Configurable rules is
+// registered with the config package so that their rules section is
+// validated when configuration is loaded.
 func Register(rule Rule) {
 	registryMu.Lock()
 	defer registryMu.Unlock()
@@ -26,6 +30,11 @@ func Register(rule Rule) {
 		panic("rule already registered: " + id)
 	}
 	registry[id] = rule
+	var defaults any
+	if c, ok := rule.(Configurable); ok {
+		defaults = c.DefaultOptions()
+	}
+	config.RegisterRule(id, rule.Name(), defaults)
 }
 
 // Rules returns all registered rules sorted by their identifiers. The returned
@@ -39,3 +48,12 @@ func Rules() []Rule {
 	}
 	sort.Slice(rules, func(i, j int) bool { return rules[i].ID() < rules[j].ID() })
 	return rules
+}
+
+// GetRule returns the registered rule with the given identifier.
+func GetRule(id string) (Rule, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	r, ok := registry[id]
+	return r, ok
+}
@@ -6,6 +6,10 @@ import (
 	"path/filepath"
 	"reflect"
 	"testing"
+
+	"github.com/asymmetric-effort/mdlint/internal/config"
+	"github.com/asymmetric-effort/mdlint/internal/findings"
+	"github.com/asymmetric-effort/mdlint/internal/parser"
 )
 
 // testRule is a simple rule used for testing registration and execution.
@@ -13,13 +17,16 @@ type testRule struct{}
 
 func (testRule) ID() string { return "test-rule" }
 
-func (testRule) Apply(node any, ctx *Context) []Finding {
-	return []Finding{{
-		RuleID:   "test-rule",
-		Location: ctx.FilePath,
-		Message:  "ok",
-		Severity: "info",
-	}}
+func (testRule) Name() string { return "Test Rule" }
+
+func (testRule) DefaultSeverity() findings.Severity { return findings.Warning }
+
+func (testRule) Apply(doc *parser.Document, cfg RuleConfig) ([]findings.Finding, error) {
+	return []findings.Finding{{
+		Message: "ok",
+		Line:    1,
+		Column:  1,
+	}}, nil
 }
 
 func init() { Register(testRule{}) }
@@ -28,7 +35,7 @@ func init() { Register(testRule{}) }
 // deterministic order.
 func TestRules(t *testing.T) {
 	rules := Rules()
-	if len(rules) != 1 || rules[0].ID() != "test-rule" {
+	if len(rules) != 2 || rules[0].ID() != DirectiveRule || rules[1].ID() != "test-rule" {
 		t.Fatalf("unexpected rules: %#v", rules)
 	}
 }
@@ -57,8 +64,121 @@ func TestRun(t *testing.T) {
 	if len(got1) != 1 {
 		t.Fatalf("expected 1 finding, got %d", len(got1))
 	}
-	if got1[0].Location != filepath.Join(dir, "a.md") {
-		t.Fatalf("unexpected finding location: %s", got1[0].Location)
+	if got1[0].File != filepath.Join(dir, "a.md") {
+		t.Fatalf("unexpected finding file: %s", got1[0].File)
+	}
+	if got1[0].Rule != "test-rule" || got1[0].Severity != findings.Warning {
+		t.Fatalf("expected engine to fill rule and severity: %+v", got1[0])
+	}
+}
+
+// TestRunGlobstar verifies that "**" and negated patterns apply to nested
+// directories.
+func TestRunGlobstar(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.md", "docs/b.md", "docs/deep/c.md", "docs/deep/keep.md"} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		mustWrite(t, filepath.Join(dir, name), "x")
+	}
+	cfg := Config{Include: []string{"docs/**/*.md"}, Exclude: []string{"deep/", "!**/keep.md"}}
+	got, err := Run(context.Background(), dir, cfg)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	var files []string
+	for _, f := range got {
+		rel, _ := filepath.Rel(dir, f.File)
+		files = append(files, filepath.ToSlash(rel))
+	}
+	if want := []string{"docs/b.md", "docs/deep/keep.md"}; !reflect.DeepEqual(files, want) {
+		t.Fatalf("got %v want %v", files, want)
+	}
+}
+
+// TestRunSeverityAndIgnored verifies that configured severities override the
+// default and that ignored rules are not executed.
+func TestRunSeverityAndIgnored(t *testing.T) {
+	dir := t.TempDir()
+	mustWrite(t, filepath.Join(dir, "a.md"), "alpha")
+
+	cfg := Config{Lint: config.Config{Severity: map[string]config.Severity{"test-rule": "error"}}}
+	got, err := Run(context.Background(), dir, cfg)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(got) != 1 || got[0].Severity != findings.Error {
+		t.Fatalf("expected severity override: %+v", got)
+	}
+
+	cfg = Config{Lint: config.Config{Ignored: []string{"test-rule"}}}
+	got, err = Run(context.Background(), dir, cfg)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("expected ignored rule to be skipped: %+v", got)
+	}
+}
+
+// TestRunFrontMatter verifies that the mdlint front-matter key configures the
+// document it appears in.
+func TestRunFrontMatter(t *testing.T) {
+	dir := t.TempDir()
+	mustWrite(t, filepath.Join(dir, "a.md"), "---\ntitle: A\nmdlint:\n  severity:\n    test-rule: error\n---\nalpha")
+	mustWrite(t, filepath.Join(dir, "b.md"), "---\nmdlint:\n  ignored: [test-rule]\n---\nbeta")
+	mustWrite(t, filepath.Join(dir, "c.md"), "gamma")
+
+	got, err := Run(context.Background(), dir, Config{})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(got) != 2 || got[0].Severity != findings.Error || got[1].Severity != findings.Warning ||
+		got[1].File != filepath.Join(dir, "c.md") {
+		t.Fatalf("unexpected findings: %+v", got)
+	}
+
+	// An invalid file is reported while the others are still linted. The
+	// position counts the lines of the document, not of its front matter.
+	bad := filepath.Join(dir, "d.md")
+	mustWrite(t, bad, "---\nmdlint:\n  unknown: true\n---\ndelta")
+	got, err = Run(context.Background(), dir, Config{})
+	if want := bad + `:3:3: front matter mdlint: unknown key "unknown"`; err == nil || err.Error() != want || !IsFileError(err) {
+		t.Fatalf("expected error %q, got %v", want, err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected the findings of the other files, got %+v", got)
+	}
+}
+
+// TestEngineRun verifies that directories are walked for Markdown files only
+// while explicitly named files are always linted.
+func TestEngineRun(t *testing.T) {
+	dir := t.TempDir()
+	mustWrite(t, filepath.Join(dir, "a.md"), "alpha")
+	mustWrite(t, filepath.Join(dir, "c.txt"), "gamma")
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	mustWrite(t, filepath.Join(dir, "sub", "b.md"), "beta")
+
+	got, err := Engine{}.Run([]string{dir, filepath.Join(dir, "c.txt")})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	want := []string{
+		filepath.Join(dir, "a.md"),
+		filepath.Join(dir, "c.txt"),
+		filepath.Join(dir, "sub", "b.md"),
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d findings, got %+v", len(want), got)
+	}
+	for i, f := range got {
+		if f.File != want[i] {
+			t.Fatalf("finding %d: got %s want %s", i, f.File, want[i])
+		}
 	}
 }
 
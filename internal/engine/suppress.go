@@ -0,0 +1,220 @@
+// Copyright 2024
+
+package engine
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/asymmetric-effort/mdlint/internal/config"
+	"github.com/asymmetric-effort/mdlint/internal/findings"
+	"github.com/asymmetric-effort/mdlint/internal/parser"
+)
+
+// Suppression directive kinds, written in HTML comments such as
+// "<!-- mdlint-disable MD1000 -->".
+const (
+	// directiveDisable disables rules from its line onward.
+	directiveDisable = "disable"
+	// directiveEnable re-enables rules from its line onward.
+	directiveEnable = "enable"
+	// directiveNextLine disables rules on the following line only.
+	directiveNextLine = "disable-next-line"
+	// directiveFile disables rules for the whole file.
+	directiveFile = "disable-file"
+)
+
+// DirectiveRule is the rule ID of findings reporting unknown or unused
+// suppression directives. They are only reported when
+// suppressions.report_unused is enabled in the configuration.
+const DirectiveRule = config.DirectiveRule
+
+// directiveRule describes DirectiveRule so that it is listed, configured and
+// ignored like other rules. Its findings are reported by suppress rather than
+// Apply.
+type directiveRule struct{}
+
+func init() { Register(directiveRule{}) }
+
+// ID returns the rule identifier.
+func (directiveRule) ID() string { return DirectiveRule }
+
+// Name returns the human readable rule name.
+func (directiveRule) Name() string { return "Suppression Directives" }
+
+// DefaultSeverity returns the severity used unless configured otherwise.
+func (directiveRule) DefaultSeverity() findings.Severity { return findings.Warning }
+
+// Apply reports nothing; directives are checked once the findings of every
+// other rule are known.
+func (directiveRule) Apply(*parser.Document, RuleConfig) ([]findings.Finding, error) {
+	return nil, nil
+}
+
+// directiveRE matches the text of a suppression comment. Rule IDs are
+// separated by spaces or commas; without IDs a directive applies to every
+// rule.
+var directiveRE = regexp.MustCompile(`^mdlint-(disable-next-line|disable-file|disable|enable)(?:\s+([\w\s,-]*))?$`)
+
+// directive is an inline suppression comment.
+type directive struct {
+	kind  string
+	rules []string
+	span  parser.Span
+}
+
+// appliesTo reports whether the directive names id, or names no rule at all.
+// Rule IDs are matched case-insensitively.
+func (d directive) appliesTo(id string) bool {
+	if len(d.rules) == 0 {
+		return true
+	}
+	for _, r := range d.rules {
+		if strings.EqualFold(r, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// knownRule reports whether a rule with the given ID, in any case, is
+// registered.
+func knownRule(id string) bool {
+	for _, r := range Rules() {
+		if strings.EqualFold(r.ID(), id) {
+			return true
+		}
+	}
+	return false
+}
+
+// directives returns the suppression directives of doc in source order.
+func directives(doc *parser.Document) []directive {
+	var result []directive
+	for _, c := range doc.Comments() {
+		m := directiveRE.FindStringSubmatch(c.Text)
+		if m == nil {
+			continue
+		}
+		d := directive{kind: m[1], span: c.Span}
+		for _, id := range strings.FieldsFunc(m[2], func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t' || r == '\n' || r == '\r'
+		}) {
+			d.rules = append(d.rules, id)
+		}
+		result = append(result, d)
+	}
+	return result
+}
+
+// suppressor returns the index of the directive silencing f, or -1 when f is
+// not suppressed. Range directives are evaluated in source order so that a
+// later enable cancels an earlier disable for the rules it names.
+func suppressor(ds []directive, f findings.Finding) int {
+	for i, d := range ds {
+		switch d.kind {
+		case directiveFile:
+			if d.appliesTo(f.Rule) {
+				return i
+			}
+		case directiveNextLine:
+			if f.Line == d.span.End.Line+1 && d.appliesTo(f.Rule) {
+				return i
+			}
+		}
+	}
+	by := -1
+	for i, d := range ds {
+		if d.span.Start.Line > f.Line {
+			break
+		}
+		switch {
+		case d.kind == directiveDisable && d.appliesTo(f.Rule):
+			by = i
+		case d.kind == directiveEnable && d.appliesTo(f.Rule):
+			by = -1
+		}
+	}
+	return by
+}
+
+// suppress removes the findings silenced by the directives of doc. When
+// report is true, findings with the given severity are added for directives
+// naming unknown rules or silencing nothing. Directives naming DirectiveRule
+// silence those findings too.
+func suppress(doc *parser.Document, fs []findings.Finding, report bool, sev findings.Severity) []findings.Finding {
+	ds := directives(doc)
+	if len(ds) == 0 {
+		return fs
+	}
+	used := make([]bool, len(ds))
+	kept := fs[:0]
+	for _, f := range fs {
+		if i := suppressor(ds, f); i >= 0 {
+			used[i] = true
+			continue
+		}
+		kept = append(kept, f)
+	}
+	if !report {
+		return kept
+	}
+	type pending struct {
+		f      findings.Finding
+		by     int  // the directive reported
+		unused bool // whether f reports the directive as unused
+	}
+	var reported []pending
+	for i, d := range ds {
+		unknown := false
+		for _, id := range d.rules {
+			if !knownRule(id) {
+				unknown = true
+				reported = append(reported, pending{directiveFinding(doc, d, sev,
+					fmt.Sprintf("suppression directive names unknown rule %q", id)), i, false})
+			}
+		}
+		if !unknown && !used[i] && d.kind != directiveEnable {
+			reported = append(reported, pending{directiveFinding(doc, d, sev,
+				fmt.Sprintf("mdlint-%s directive suppresses no findings", d.kind)), i, true})
+		}
+	}
+	// Silence directive findings first, as doing so uses the directive.
+	live := reported[:0]
+	for _, p := range reported {
+		if i := suppressor(ds, p.f); i >= 0 {
+			used[i] = true
+			continue
+		}
+		live = append(live, p)
+	}
+	for _, p := range live {
+		if !p.unused || !used[p.by] {
+			kept = append(kept, p.f)
+		}
+	}
+	return kept
+}
+
+// directiveFinding returns a finding reporting d, with a suggestion removing
+// the directive. A directive alone on its line is removed with its line.
+func directiveFinding(doc *parser.Document, d directive, sev findings.Severity, msg string) findings.Finding {
+	start, end := d.span.Start.Offset, d.span.End.Offset
+	first := doc.LineOffset(d.span.Start.Line)
+	last := doc.LineOffset(d.span.End.Line) + len(doc.Line(d.span.End.Line))
+	if strings.TrimSpace(string(doc.Source[first:start])) == "" &&
+		strings.TrimSpace(string(doc.Source[end:last])) == "" {
+		start = first
+		end = last
+		if end < len(doc.Source) {
+			end++ // the line ending
+		}
+	}
+	f := Locate(findings.Finding{Rule: DirectiveRule, Severity: sev, File: doc.Path, Message: msg}, d.span)
+	f.Suggestions = []findings.SuggestedFix{{
+		Label: "Remove the directive",
+		Edits: []findings.Edit{Replace(doc.Span(start, end), "")},
+	}}
+	return f
+}
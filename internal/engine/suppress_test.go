@@ -0,0 +1,134 @@
+// Copyright 2025 Sam Caldwell
+// SPDX-License-Identifier: MIT
+
+package engine
+
+import (
+	"testing"
+
+	"github.com/asymmetric-effort/mdlint/internal/config"
+	"github.com/asymmetric-effort/mdlint/internal/findings"
+	"github.com/asymmetric-effort/mdlint/internal/parser"
+)
+
+func TestSuppress(t *testing.T) {
+	src := `line 1
+<!-- mdlint-disable MD1000 -->
+line 3
+<!-- mdlint-enable -->
+line 5
+<!-- mdlint-disable-next-line md1100, MD1500 -->
+line 7
+line 8
+<!-- mdlint-disable -->
+line 10
+<!-- mdlint-enable MD1800 -->
+line 12
+` + "```" + `
+<!-- mdlint-enable -->
+` + "```" + `
+line 16
+`
+	doc := parser.Parse("", []byte(src))
+	tests := []struct {
+		rule       string
+		line       int
+		suppressed bool
+	}{
+		{"MD1000", 1, false},
+		{"MD1000", 3, true},
+		{"MD1100", 3, false},
+		{"MD1000", 5, false},
+		{"MD1100", 7, true},
+		{"MD1500", 7, true},
+		{"MD1000", 7, false},
+		{"MD1100", 8, false},
+		{"MD1000", 10, true},
+		{"MD1800", 10, true},
+		{"MD1800", 12, false},
+		{"MD1000", 12, true},
+		{"MD1000", 16, true}, // the enable inside a code block is ignored
+	}
+	ds := directives(doc)
+	if len(ds) != 5 {
+		t.Fatalf("expected 5 directives, got %+v", ds)
+	}
+	for _, tt := range tests {
+		f := findings.Finding{Rule: tt.rule, Line: tt.line}
+		if got := suppressor(ds, f) >= 0; got != tt.suppressed {
+			t.Errorf("%s on line %d: suppressed = %v, want %v", tt.rule, tt.line, got, tt.suppressed)
+		}
+	}
+}
+
+func TestSuppressFile(t *testing.T) {
+	doc := parser.Parse("", []byte("text\n\n<!-- mdlint-disable-file MD1800 -->\n"))
+	fs := []findings.Finding{{Rule: "MD1800", Line: 1}, {Rule: "MD1000", Line: 1}}
+	got := suppress(doc, fs, false, findings.Warning)
+	if len(got) != 1 || got[0].Rule != "MD1000" {
+		t.Fatalf("unexpected findings: %+v", got)
+	}
+}
+
+func TestSuppressReport(t *testing.T) {
+	src := "<!-- mdlint-disable-next-line Test-Rule -->\nline 2\n\n<!-- mdlint-disable test-rule -->\n\nText <!-- mdlint-disable MD9999 --> here.\n"
+	doc := parser.Parse("a.md", []byte(src))
+	fs := []findings.Finding{{Rule: "test-rule", Line: 2}}
+	got := suppress(doc, fs, true, findings.Error)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 directive findings, got %+v", got)
+	}
+	unused, unknown := got[0], got[1]
+	if unused.Rule != DirectiveRule || unused.Line != 4 || unused.Severity != findings.Error || unused.File != "a.md" ||
+		unused.Message != "mdlint-disable directive suppresses no findings" {
+		t.Fatalf("unexpected unused finding: %+v", unused)
+	}
+	if e := unused.Suggestions[0].Edits[0]; src[e.Offset:e.Offset+e.Length] != "<!-- mdlint-disable test-rule -->\n" {
+		t.Fatalf("unexpected removal edit: %+v", e)
+	}
+	if unknown.Line != 6 || unknown.Message != `suppression directive names unknown rule "MD9999"` {
+		t.Fatalf("unexpected unknown finding: %+v", unknown)
+	}
+	if e := unknown.Suggestions[0].Edits[0]; src[e.Offset:e.Offset+e.Length] != "<!-- mdlint-disable MD9999 -->" {
+		t.Fatalf("unexpected removal edit: %+v", e)
+	}
+}
+
+func TestSuppressDirectiveRule(t *testing.T) {
+	src := "<!-- mdlint-disable-next-line MD0001 -->\n<!-- mdlint-disable MD9999 -->\n\n<!-- mdlint-disable test-rule -->\n"
+	got := suppress(parser.Parse("a.md", []byte(src)), nil, true, findings.Warning)
+	if len(got) != 1 || got[0].Line != 4 {
+		t.Fatalf("expected only the unused directive on line 4, got %+v", got)
+	}
+
+	src = "<!-- mdlint-disable-file MD0001 -->\n<!-- mdlint-disable test-rule -->\n"
+	if got := suppress(parser.Parse("a.md", []byte(src)), nil, true, findings.Warning); len(got) != 0 {
+		t.Fatalf("expected directive findings to be silenced, got %+v", got)
+	}
+}
+
+func TestLintSourceIgnoresDirectiveRule(t *testing.T) {
+	report := true
+	lint := config.Config{Suppressions: config.SuppressionConfig{ReportUnused: &report}}
+	src := []byte("<!-- mdlint-disable MD9999 -->\nalpha\n")
+	got, err := lintSource("a.md", src, lint)
+	if err != nil {
+		t.Fatalf("lint: %v", err)
+	}
+	if !hasRule(got, DirectiveRule) {
+		t.Fatalf("expected a directive finding, got %+v", got)
+	}
+	lint.Ignored = []string{DirectiveRule}
+	if got, err = lintSource("a.md", src, lint); err != nil || hasRule(got, DirectiveRule) {
+		t.Fatalf("expected no directive findings, got %+v, %v", got, err)
+	}
+}
+
+func hasRule(fs []findings.Finding, id string) bool {
+	for _, f := range fs {
+		if f.Rule == id {
+			return true
+		}
+	}
+	return false
+}
@@ -2,32 +2,106 @@
 
 package engine
 
-// Rule defines a linting rule that can be applied to a node within a file.
-// ID must return a unique identifier for the rule. Apply evaluates the rule
-// against the provided node and returns any findings.
+import (
+	"github.com/asymmetric-effort/mdlint/internal/config"
+	"github.com/asymmetric-effort/mdlint/internal/findings"
+	"github.com/asymmetric-effort/mdlint/internal/parser"
+)
+
+// Rule defines a linting rule that can be applied to a parsed document.
+// Every built-in rule implements Rule and registers itself with Register;
+// third-party rules are written against the same contract.
 type Rule interface {
-	// ID returns the unique identifier of the rule.
+	// ID returns the unique identifier of the rule, e.g. "MD1100".
 	ID() string
-	// Apply evaluates the rule against the given node and context. It returns
-	// zero or more findings describing rule violations.
-	Apply(node any, ctx *Context) []Finding
-}
-
-// Context carries information about the file being processed.
-type Context struct {
-	// FilePath is the absolute path to the file currently being linted.
-	FilePath string
-}
-
-// Finding describes a rule violation discovered during linting.
-type Finding struct {
-	// RuleID is the identifier of the rule that produced the finding.
-	RuleID string
-	// Location describes where the finding occurred. Typically this is a file
-	// path optionally followed by a line number.
-	Location string
-	// Message explains the nature of the finding.
-	Message string
-	// Severity indicates the importance of the finding.
-	Severity string
+	// Name returns a short human readable label for the rule.
+	Name() string
+	// DefaultSeverity returns the severity used when the configuration does
+	// not override it.
+	DefaultSeverity() findings.Severity
+	// Apply evaluates the rule against the given document using the
+	// effective configuration for that document. It returns zero or more
+	// findings describing rule violations. The engine fills in the rule ID,
+	// file and severity when a finding leaves them empty. The document is
+	// shared between rules and must not be modified.
+	Apply(doc *parser.Document, cfg RuleConfig) ([]findings.Finding, error)
+}
+
+// Configurable is implemented by rules that accept typed options.
+type Configurable interface {
+	// DefaultOptions returns the rule's options struct populated with its
+	// defaults. The engine passes a value of the same type in
+	// RuleConfig.Options, with the settings of the rule's entry in the
+	// rules section of the configuration applied. Fields are named by their
+	// yaml tags there.
+	DefaultOptions() any
+}
+
+// Settable is implemented by configurable rules whose options are set from a
+// dedicated section of the configuration, such as heading, rather than only
+// from their defaults.
+type Settable interface {
+	Configurable
+	// OptionsFrom returns the rule's options for the effective configuration
+	// of a document.
+	OptionsFrom(lint config.Config) any
+}
+
+// Fixable is implemented by rules whose suggestions are safe to apply without
+// review. Engine.Fix applies the first suggestion of findings reported by
+// fixable rules only.
+type Fixable interface {
+	// Fixable reports whether the rule's suggestions may be applied
+	// automatically.
+	Fixable() bool
+}
+
+// RuleConfig is the effective configuration of a rule for one document.
+type RuleConfig struct {
+	// Severity is the severity assigned to the rule's findings.
+	Severity findings.Severity
+	// Options holds the rule's typed options for rules implementing
+	// Configurable and is nil otherwise.
+	Options any
+}
+
+// ruleConfig returns the effective configuration of r for a document linted
+// with lint. Options start from the rule's defaults, or the dedicated section
+// of Settable rules, with the rule's rules section decoded on top.
+func ruleConfig(r Rule, lint config.Config) (RuleConfig, error) {
+	cfg := RuleConfig{Severity: findings.Severity(lint.Severity[r.ID()])}
+	if cfg.Severity == "" {
+		cfg.Severity = r.DefaultSeverity()
+	}
+	switch c := r.(type) {
+	case Settable:
+		cfg.Options = c.OptionsFrom(lint)
+	case Configurable:
+		cfg.Options = c.DefaultOptions()
+	}
+	var err error
+	cfg.Options, err = lint.RuleOptions(r.ID(), cfg.Options)
+	return cfg, err
+}
+
+// Locate returns f positioned at the start of s and covering its range.
+// Rules use it to report the full extent of the offending text.
+func Locate(f findings.Finding, s parser.Span) findings.Finding {
+	f.Line, f.Column = s.Start.Line, s.Start.Column
+	f.EndLine, f.EndColumn = s.End.Line, s.End.Column
+	f.Offset, f.Length = s.Start.Offset, s.Len()
+	return f
+}
+
+// Replace returns an edit replacing the text covered by s with newText.
+func Replace(s parser.Span, newText string) findings.Edit {
+	return findings.Edit{
+		Line:      s.Start.Line,
+		Column:    s.Start.Column,
+		EndLine:   s.End.Line,
+		EndColumn: s.End.Column,
+		Offset:    s.Start.Offset,
+		Length:    s.Len(),
+		NewText:   newText,
+	}
 }
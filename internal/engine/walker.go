@@ -5,30 +5,44 @@ package engine
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
 	"runtime"
 	"sort"
+	"sync"
 
 	"golang.org/x/sync/errgroup"
+
+	"github.com/asymmetric-effort/mdlint/internal/config"
+	"github.com/asymmetric-effort/mdlint/internal/findings"
+	"github.com/asymmetric-effort/mdlint/internal/glob"
+	"github.com/asymmetric-effort/mdlint/internal/parser"
 )
 
 // Config controls how files are discovered during a run.
 type Config struct {
-	// Include defines glob patterns of files to include. If empty, all files are
-	// included.
+	// Include defines glob patterns of files to include, matched against paths
+	// relative to the root as a glob.List. If empty, all files are included.
 	Include []string
-	// Exclude defines glob patterns of files or directories to exclude.
+	// Exclude defines glob patterns of files or directories to exclude, in the
+	// same syntax as Include.
 	Exclude []string
 	// Workers controls the number of concurrent workers used to process files.
 	// If zero, runtime.NumCPU is used.
 	Workers int
+	// Lint holds the rule configuration (ignored rules and severities) applied
+	// to every file, specialized per file by nested project files, paths
+	// overrides and front matter.
+	Lint config.Config
 }
 
 // Run walks the file tree rooted at root, applying all registered rules to
-// matching files. Findings are returned in a deterministic order.
-func Run(ctx context.Context, root string, cfg Config) ([]Finding, error) {
+// matching files. Findings are returned in a deterministic order. Files with
+// invalid front matter are skipped and reported by *FileError values joined
+// into the returned error, alongside the findings of the other files.
+func Run(ctx context.Context, root string, cfg Config) ([]findings.Finding, error) {
 	if root == "" {
 		return nil, errors.New("root must not be empty")
 	}
@@ -47,8 +61,15 @@ func Run(ctx context.Context, root string, cfg Config) ([]Finding, error) {
 			if err != nil {
 				return err
 			}
+			if rel == "." {
+				// The root itself; a file root is matched by its name.
+				if d.IsDir() {
+					return nil
+				}
+				rel = filepath.Base(path)
+			}
 			if d.IsDir() {
-				if matchPattern(rel, cfg.Exclude) {
+				if glob.List(cfg.Exclude).Prune(filepath.ToSlash(rel)) {
 					return filepath.SkipDir
 				}
 				return nil
@@ -70,26 +91,30 @@ func Run(ctx context.Context, root string, cfg Config) ([]Finding, error) {
 		workerCount = runtime.NumCPU()
 	}
 
-	findingsCh := make(chan Finding)
+	findingsCh := make(chan findings.Finding)
+	var (
+		skippedMu sync.Mutex
+		skipped   []error
+	)
 
 	for i := 0; i < workerCount; i++ {
 		g.Go(func() error {
 			for path := range paths {
-				content, err := os.ReadFile(path)
+				found, err := lintFile(path, cfg.Lint)
+				if IsFileError(err) {
+					skippedMu.Lock()
+					skipped = append(skipped, err)
+					skippedMu.Unlock()
+					continue
+				}
 				if err != nil {
 					return err
 				}
-				fctx := &Context{FilePath: path}
-				for _, r := range Rules() {
-					for _, f := range r.Apply(content, fctx) {
-						if f.Location == "" {
-							f.Location = path
-						}
-						select {
-						case findingsCh <- f:
-						case <-ctx.Done():
-							return ctx.Err()
-						}
+				for _, f := range found {
+					select {
+					case findingsCh <- f:
+					case <-ctx.Done():
+						return ctx.Err()
 					}
 				}
 			}
@@ -97,11 +122,11 @@ func Run(ctx context.Context, root string, cfg Config) ([]Finding, error) {
 		})
 	}
 
-	var findings []Finding
+	var result []findings.Finding
 	collectDone := make(chan struct{})
 	go func() {
 		for f := range findingsCh {
-			findings = append(findings, f)
+			result = append(result, f)
 		}
 		close(collectDone)
 	}()
@@ -114,31 +139,148 @@ func Run(ctx context.Context, root string, cfg Config) ([]Finding, error) {
 	close(findingsCh)
 	<-collectDone
 
-	sort.Slice(findings, func(i, j int) bool {
-		if findings[i].Location == findings[j].Location {
-			return findings[i].RuleID < findings[j].RuleID
-		}
-		return findings[i].Location < findings[j].Location
+	sortFindings(result)
+	sort.Slice(skipped, func(i, j int) bool {
+		return skipped[i].(*FileError).Path < skipped[j].(*FileError).Path
 	})
+	return result, errors.Join(skipped...)
+}
+
+// lintFile parses the file at path once and applies every enabled rule to the
+// resulting document. Rule IDs, file names and severities missing from a
+// rule's findings are filled in here so that all findings leave the engine
+// complete.
+func lintFile(path string, lint config.Config) ([]findings.Finding, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return lintSource(path, content, lint)
+}
+
+// lintSource applies every enabled rule to content read from path, using the
+// overrides of lint matching path and the settings under the reserved mdlint
+// key of the document's front matter, in that order. Findings silenced by
+// inline suppression comments are dropped and, when configured, unknown or
+// unused directives are reported.
+func lintSource(path string, content []byte, lint config.Config) ([]findings.Finding, error) {
+	doc := parser.Parse(path, content)
+	lint, err := fileConfig(doc, lint)
+	if IsFileError(err) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	var result []findings.Finding
+	for _, r := range Rules() {
+		id := r.ID()
+		if contains(lint.Ignored, id) {
+			continue
+		}
+		rcfg, err := ruleConfig(r, lint)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		found, err := r.Apply(doc, rcfg)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", path, id, err)
+		}
+		for _, f := range found {
+			if f.Rule == "" {
+				f.Rule = id
+			}
+			if f.File == "" {
+				f.File = path
+			}
+			if f.Severity == "" {
+				f.Severity = rcfg.Severity
+			}
+			result = append(result, f)
+		}
+	}
+	report := lint.Suppressions.ReportUnused != nil && *lint.Suppressions.ReportUnused &&
+		!contains(lint.Ignored, DirectiveRule)
+	sev := findings.Severity(lint.Severity[DirectiveRule])
+	if sev == "" {
+		sev = findings.Warning
+	}
+	return suppress(doc, result, report, sev), nil
+}
+
+// ConfigFor returns the effective configuration used to lint the file at
+// path: lint resolved for the file's directory and path, with the settings of
+// its front matter merged on top.
+func ConfigFor(path string, lint config.Config) (config.Config, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return config.Config{}, err
+	}
+	return fileConfig(parser.Parse(path, content), lint)
+}
+
+// fileConfig returns the configuration for doc: lint resolved for its path,
+// including nested project files and path overrides, with the per-file
+// settings of its front matter merged on top.
+func fileConfig(doc *parser.Document, lint config.Config) (config.Config, error) {
+	lint, err := lint.Resolve(doc.Path)
+	if err != nil {
+		return config.Config{}, err
+	}
+	fc, ok, err := config.ParseFrontMatter(doc.FrontMatterSource())
+	if err != nil {
+		// Report the position within the document rather than within its
+		// front matter, which starts after the opening delimiter.
+		var cfgErr *config.Error
+		if !errors.As(err, &cfgErr) {
+			cfgErr = &config.Error{Err: err}
+		} else if cfgErr.Line > 0 {
+			cfgErr.Line += doc.FrontMatter.StartLine
+		}
+		cfgErr.File = doc.Path
+		return config.Config{}, &FileError{Path: doc.Path, Err: cfgErr}
+	}
+	if !ok {
+		return lint, nil
+	}
+	return lint.WithFile(fc), nil
+}
 
-	return findings, nil
+// sortFindings orders findings by file, position, rule and message.
+func sortFindings(fs []findings.Finding) {
+	sort.Slice(fs, func(i, j int) bool {
+		a, b := fs[i], fs[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		if a.Column != b.Column {
+			return a.Column < b.Column
+		}
+		if a.Rule != b.Rule {
+			return a.Rule < b.Rule
+		}
+		return a.Message < b.Message
+	})
 }
 
 // shouldInclude reports whether the given relative path should be processed.
+// Include and Exclude are glob.List patterns, so "**" spans directories and
+// "!" patterns re-include paths matched earlier in the same list.
 func shouldInclude(rel string, cfg Config) bool {
-	if len(cfg.Include) > 0 && !matchPattern(rel, cfg.Include) {
-		return false
-	}
-	if matchPattern(rel, cfg.Exclude) {
+	rel = filepath.ToSlash(rel)
+	if len(cfg.Include) > 0 && !glob.List(cfg.Include).Match(rel) {
 		return false
 	}
-	return true
+	return !glob.List(cfg.Exclude).Match(rel)
 }
 
-// matchPattern reports whether name matches any of the provided glob patterns.
-func matchPattern(name string, patterns []string) bool {
-	for _, p := range patterns {
-		if ok, _ := filepath.Match(p, name); ok {
+// contains reports whether list holds s.
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
 			return true
 		}
 	}
@@ -1,12 +1,69 @@
-right (c) 2024
+// Copyright (c) 2024
 
 package findings
 
+import "encoding/json"
+
 // Finding represents a single rule violation.
+//
+// Line and Column locate the start of the violation. Rules that know the
+// extent of the offending text also set EndLine and EndColumn, the position
+// just past its last byte, and the equivalent byte Offset and Length within
+// the file. A zero Length means the finding has no range. Suggestions hold
+// optional fixes a user or tool may apply.
 type Finding struct {
-	Rule     string   `json:"rule"`
-	Severity Severity `json:"severity"`
-	Message  string   `json:"message"`
-	File     string   `json:"file"`
-	Line     int      `json:"line"`
-	Column   int      `json:"column"`
+	Rule      string   `json:"rule"`
+	Severity  Severity `json:"severity"`
+	Message   string   `json:"message"`
+	File      string   `json:"file"`
+	Line      int      `json:"line"`
+	Column    int      `json:"column"`
+	EndLine   int      `json:"end_line,omitempty"`
+	EndColumn int      `json:"end_column,omitempty"`
+	Offset    int      `json:"offset,omitempty"`
+	Length    int      `json:"length,omitempty"`
+
+	Suggestions []SuggestedFix `json:"suggestions,omitempty"`
+}
+
+// MarshalJSON encodes the finding, always including the offset of a ranged
+// finding, which omitempty would drop at the start of the file.
+func (f Finding) MarshalJSON() ([]byte, error) {
+	type fields Finding
+	out := struct {
+		fields
+		Offset      *int           `json:"offset,omitempty"`
+		Length      int            `json:"length,omitempty"`
+		Suggestions []SuggestedFix `json:"suggestions,omitempty"`
+	}{fields: fields(f), Length: f.Length, Suggestions: f.Suggestions}
+	if f.Length > 0 {
+		out.Offset = &f.Offset
+	}
+	return json.Marshal(out)
+}
+
+// HasRange reports whether the finding covers a range of text rather than a
+// single position.
+func (f Finding) HasRange() bool { return f.EndLine > 0 }
+
+// SuggestedFix is a proposed change resolving a finding.
+type SuggestedFix struct {
+	// Label describes the change to a user, e.g. "Replace with 'email'".
+	Label string `json:"label"`
+	// Edits lists the non-overlapping text edits making up the change.
+	Edits []Edit `json:"edits"`
+}
+
+// Edit replaces the source range [Offset, Offset+Length) with NewText. The
+// range is also given as lines and columns, using the same conventions as
+// Finding. A zero Length inserts NewText at Offset; an empty NewText deletes
+// the range.
+type Edit struct {
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+	EndLine   int    `json:"end_line"`
+	EndColumn int    `json:"end_column"`
+	Offset    int    `json:"offset"`
+	Length    int    `json:"length"`
+	NewText   string `json:"new_text"`
+}
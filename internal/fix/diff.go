@@ -0,0 +1,338 @@
+// Copyright (c) 2024
+
+package fix
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// contextLines is the number of unchanged lines shown around each change.
+const contextLines = 3
+
+// op is a single step of a line edit script.
+type op struct {
+	kind byte // ' ' keeps a line, '-' deletes it and '+' inserts it
+	line string
+}
+
+// Diff returns a unified diff turning before into after. Files within the
+// working directory are named relative to it with the "a/" and "b/" prefixes
+// understood by git apply; others keep their absolute path without prefixes.
+// It returns "" when the contents are equal.
+func Diff(path string, before, after []byte) string {
+	if bytes.Equal(before, after) {
+		return ""
+	}
+	from, to := diffNames(path)
+	ops := diffLines(splitLines(before), splitLines(after))
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", from, to)
+	for start := 0; start < len(ops); {
+		// Find the next change and the extent of its hunk.
+		first := start
+		for first < len(ops) && ops[first].kind == ' ' {
+			first++
+		}
+		if first == len(ops) {
+			break
+		}
+		end := first
+		for i := first; i < len(ops); i++ {
+			if ops[i].kind != ' ' {
+				end = i + 1
+				continue
+			}
+			if i-end >= 2*contextLines {
+				break
+			}
+		}
+		lo := max(first-contextLines, start)
+		hi := min(end+contextLines, len(ops))
+		writeHunk(&sb, ops, lo, hi)
+		start = hi
+	}
+	return sb.String()
+}
+
+// diffNames returns the old and new file names of path in a diff header.
+func diffNames(path string) (string, string) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path, path
+	}
+	if wd, err := os.Getwd(); err == nil {
+		rel, err := filepath.Rel(wd, abs)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			rel = filepath.ToSlash(rel)
+			return "a/" + rel, "b/" + rel
+		}
+	}
+	abs = filepath.ToSlash(abs)
+	return abs, abs
+}
+
+// writeHunk writes ops[lo:hi] as one hunk with its header.
+func writeHunk(sb *strings.Builder, ops []op, lo, hi int) {
+	oldStart, newStart := 1, 1
+	for _, o := range ops[:lo] {
+		if o.kind != '+' {
+			oldStart++
+		}
+		if o.kind != '-' {
+			newStart++
+		}
+	}
+	oldCount, newCount := 0, 0
+	for _, o := range ops[lo:hi] {
+		if o.kind != '+' {
+			oldCount++
+		}
+		if o.kind != '-' {
+			newCount++
+		}
+	}
+	// An empty range is numbered by the line preceding it.
+	if oldCount == 0 {
+		oldStart--
+	}
+	if newCount == 0 {
+		newStart--
+	}
+	fmt.Fprintf(sb, "@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount))
+	for _, o := range ops[lo:hi] {
+		sb.WriteByte(o.kind)
+		sb.WriteString(o.line)
+		if !strings.HasSuffix(o.line, "\n") {
+			sb.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+func hunkRange(start, count int) string {
+	if count == 1 {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// splitLines splits b into lines, keeping each line's terminating newline.
+func splitLines(b []byte) []string {
+	var lines []string
+	for len(b) > 0 {
+		i := bytes.IndexByte(b, '\n') + 1
+		if i == 0 {
+			i = len(b)
+		}
+		lines = append(lines, string(b[:i]))
+		b = b[i:]
+	}
+	return lines
+}
+
+// maxMyersLines bounds the lines of a region diffed with Myers' algorithm,
+// whose trace grows with the square of the number of changes.
+const maxMyersLines = 1000
+
+// pair holds the indexes of a line of a and a line of b.
+type pair struct{ x, y int }
+
+// diffLines returns an edit script turning a into b. Lines occurring once in
+// each input anchor the diff, as in patience diff, and every anchor is
+// extended over the equal lines around it. The regions left between anchors
+// are diffed line by line when their lengths match, which is the common case
+// for fixes editing within lines, and with Myers' algorithm otherwise. This
+// keeps large files with changes on every line fast.
+func diffLines(a, b []string) []op {
+	var ops []op
+	done := pair{}
+	anchors := append(append([]pair{{}}, unique(a, b)...), pair{len(a), len(b)})
+	for _, p := range anchors {
+		if p.x < done.x {
+			// Already covered by extending an earlier anchor.
+			continue
+		}
+		start := p
+		for start.x > done.x && start.y > done.y && a[start.x-1] == b[start.y-1] {
+			start.x--
+			start.y--
+		}
+		end := p
+		for end.x < len(a) && end.y < len(b) && a[end.x] == b[end.y] {
+			end.x++
+			end.y++
+		}
+		ops = append(ops, diffRegion(a[done.x:start.x], b[done.y:start.y])...)
+		for _, line := range a[start.x:end.x] {
+			ops = append(ops, op{' ', line})
+		}
+		done = end
+	}
+	return ops
+}
+
+// unique returns the pairs of lines occurring exactly once in both a and b
+// that form the longest sequence ordered in both inputs.
+func unique(a, b []string) []pair {
+	type count struct{ na, nb, y int }
+	counts := map[string]*count{}
+	for _, line := range a {
+		c := counts[line]
+		if c == nil {
+			c = &count{}
+			counts[line] = c
+		}
+		c.na++
+	}
+	for y, line := range b {
+		if c := counts[line]; c != nil {
+			c.nb++
+			c.y = y
+		}
+	}
+	var pairs []pair
+	for x, line := range a {
+		if c := counts[line]; c.na == 1 && c.nb == 1 {
+			pairs = append(pairs, pair{x, c.y})
+		}
+	}
+
+	// Patience sorting: tails[i] is the pair ending the best increasing
+	// sequence of length i+1 found so far.
+	var tails []int
+	prev := make([]int, len(pairs))
+	for i, p := range pairs {
+		n := sort.Search(len(tails), func(j int) bool { return pairs[tails[j]].y > p.y })
+		prev[i] = -1
+		if n > 0 {
+			prev[i] = tails[n-1]
+		}
+		if n == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[n] = i
+		}
+	}
+	if len(tails) == 0 {
+		return nil
+	}
+	seq := make([]pair, len(tails))
+	for i, j := len(seq)-1, tails[len(tails)-1]; i >= 0; i, j = i-1, prev[j] {
+		seq[i] = pairs[j]
+	}
+	return seq
+}
+
+// diffRegion returns an edit script turning a into b, two regions sharing no
+// anchor. Regions of the same length are compared line by line.
+func diffRegion(a, b []string) []op {
+	var ops []op
+	switch {
+	case len(a) == len(b):
+		for i := 0; i < len(a); {
+			if a[i] == b[i] {
+				ops = append(ops, op{' ', a[i]})
+				i++
+				continue
+			}
+			j := i
+			for j < len(a) && a[j] != b[j] {
+				j++
+			}
+			ops = appendChange(ops, a[i:j], b[i:j])
+			i = j
+		}
+	case len(a)+len(b) <= maxMyersLines:
+		ops = myers(a, b)
+	default:
+		ops = appendChange(ops, a, b)
+	}
+	return ops
+}
+
+// appendChange appends the deletion of the lines del followed by the
+// insertion of the lines ins.
+func appendChange(ops []op, del, ins []string) []op {
+	for _, line := range del {
+		ops = append(ops, op{'-', line})
+	}
+	for _, line := range ins {
+		ops = append(ops, op{'+', line})
+	}
+	return ops
+}
+
+// myers returns a shortest edit script turning a into b using Myers' O(ND)
+// algorithm. Only the band of v used by each step is kept for the backtrack.
+func myers(a, b []string) []op {
+	n, m := len(a), len(b)
+	maxD := n + m
+	offset := maxD + 1
+	v := make([]int, 2*maxD+3)
+	var trace [][]int
+	for d := 0; d <= maxD; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, a, b, d)
+			}
+		}
+	}
+	return nil
+}
+
+// backtrack walks the saved Myers traces back from the end of both inputs to
+// recover the edit script. trace[d] holds v[-d:d+1] as step d began.
+func backtrack(trace [][]int, a, b []string, d int) []op {
+	var ops []op
+	x, y := len(a), len(b)
+	for ; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[d+k-1] < v[d+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[d+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ops = append(ops, op{' ', a[x]})
+		}
+		if x == prevX {
+			y--
+			ops = append(ops, op{'+', b[y]})
+		} else {
+			x--
+			ops = append(ops, op{'-', a[x]})
+		}
+	}
+	for x > 0 {
+		x--
+		ops = append(ops, op{' ', a[x]})
+	}
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
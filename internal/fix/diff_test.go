@@ -0,0 +1,153 @@
+// Copyright 2025 Sam Caldwell
+// SPDX-License-Identifier: MIT
+
+package fix
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	var lines []string
+	for i := 1; i <= 20; i++ {
+		lines = append(lines, fmt.Sprintf("line %d\n", i))
+	}
+	before := strings.Join(lines, "")
+	lines[1] = "line two\n"
+	lines[17] = "line eighteen\n"
+	after := strings.Join(lines, "")
+
+	want := `--- a/docs/a.md
++++ b/docs/a.md
+@@ -1,5 +1,5 @@
+ line 1
+-line 2
++line two
+ line 3
+ line 4
+ line 5
+@@ -15,6 +15,6 @@
+ line 15
+ line 16
+ line 17
+-line 18
++line eighteen
+ line 19
+ line 20
+`
+	if got := Diff("./docs/a.md", []byte(before), []byte(after)); got != want {
+		t.Fatalf("unexpected diff:\n%s", got)
+	}
+	if got := Diff("a.md", []byte(before), []byte(before)); got != "" {
+		t.Fatalf("expected empty diff, got:\n%s", got)
+	}
+}
+
+func TestDiffNoTrailingNewline(t *testing.T) {
+	got := Diff("a.md", []byte("one\ntwo "), []byte("one\ntwo"))
+	want := "--- a/a.md\n+++ b/a.md\n@@ -1,2 +1,2 @@\n one\n-two \n\\ No newline at end of file\n+two\n\\ No newline at end of file\n"
+	if got != want {
+		t.Fatalf("unexpected diff:\n%s", got)
+	}
+	got = Diff("a.md", nil, []byte("new\n"))
+	if want := "--- a/a.md\n+++ b/a.md\n@@ -0,0 +1 @@\n+new\n"; got != want {
+		t.Fatalf("unexpected diff:\n%s", got)
+	}
+}
+
+// TestDiffLarge diffs large files whose every line changes, as removing
+// trailing whitespace throughout a document does.
+func TestDiffLarge(t *testing.T) {
+	var before, after []string
+	for i := 0; i < 20000; i++ {
+		before = append(before, fmt.Sprintf("line %d  \n", i), "\n")
+		after = append(after, fmt.Sprintf("line %d\n", i), "\n")
+	}
+	for _, tt := range []struct {
+		name  string
+		after []string
+	}{
+		{"same length", after},
+		{"inserted line", append([]string{"# Title\n"}, after...)},
+	} {
+		ops := diffLines(before, tt.after)
+		var old, new []string
+		changed := 0
+		for _, o := range ops {
+			if o.kind != '+' {
+				old = append(old, o.line)
+			}
+			if o.kind != '-' {
+				new = append(new, o.line)
+			}
+			if o.kind == '-' {
+				changed++
+			}
+		}
+		if strings.Join(old, "") != strings.Join(before, "") || strings.Join(new, "") != strings.Join(tt.after, "") {
+			t.Fatalf("%s: edit script does not turn before into after", tt.name)
+		}
+		if tt.name == "same length" && changed != 20000 {
+			t.Fatalf("%s: %d lines deleted, want 20000", tt.name, changed)
+		}
+	}
+}
+
+// TestDiffGitApply checks that git accepts the generated patch from the
+// working directory, for relative and absolute paths.
+func TestDiffGitApply(t *testing.T) {
+	git, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	t.Chdir(dir)
+	before := "# Title\n\ntext \n\nmore\n"
+	after := "# Title\n\ntext\n\nmore\n"
+	for _, path := range []string{"docs/a.md", filepath.Join(dir, "docs", "a.md")} {
+		if err := os.MkdirAll(filepath.Join(dir, "docs"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(before), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		diff := Diff(path, []byte(before), []byte(after))
+		if !strings.HasPrefix(diff, "--- a/docs/a.md\n+++ b/docs/a.md\n") {
+			t.Fatalf("%s: unexpected header:\n%s", path, diff)
+		}
+		patch := filepath.Join(dir, "fix.patch")
+		if err := os.WriteFile(patch, []byte(diff), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if out, err := exec.Command(git, "apply", patch).CombinedOutput(); err != nil {
+			t.Fatalf("%s: git apply: %v\n%s", path, err, out)
+		}
+		got, err := os.ReadFile(path)
+		if err != nil || string(got) != after {
+			t.Fatalf("%s: unexpected patched file %q: %v", path, got, err)
+		}
+	}
+}
+
+// TestDiffOutside checks that files outside the working directory keep their
+// absolute path.
+func TestDiffOutside(t *testing.T) {
+	dir := t.TempDir()
+	work := filepath.Join(dir, "work")
+	if err := os.Mkdir(work, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(work)
+	name := filepath.ToSlash(filepath.Join(dir, "a.md"))
+	want := "--- " + name + "\n+++ " + name + "\n"
+	for _, path := range []string{"../a.md", filepath.Join(dir, "a.md")} {
+		if got := Diff(path, []byte("a \n"), []byte("a\n")); !strings.HasPrefix(got, want) {
+			t.Errorf("%s: unexpected header:\n%s", path, got)
+		}
+	}
+}
@@ -0,0 +1,6 @@
+// Copyright 2025 Sam Caldwell
+// SPDX-License-Identifier: MIT
+
+// Package fix applies the text edits suggested by findings to file contents
+// and writes the result back safely.
+package fix
@@ -0,0 +1,126 @@
+// Copyright (c) 2024
+
+package fix
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/asymmetric-effort/mdlint/internal/findings"
+)
+
+// Apply applies the first suggestion of every finding to src and returns the
+// edited source together with the findings whose suggestion was applied.
+// A suggestion is applied as a whole or not at all: when any of its edits
+// overlaps an edit already accepted, the suggestion is skipped so that it can
+// be reconsidered against the edited source. Findings are considered in
+// source order.
+func Apply(src []byte, fs []findings.Finding) ([]byte, []findings.Finding) {
+	ordered := make([]findings.Finding, 0, len(fs))
+	for _, f := range fs {
+		if len(f.Suggestions) > 0 && len(f.Suggestions[0].Edits) > 0 {
+			ordered = append(ordered, f)
+		}
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return firstOffset(ordered[i]) < firstOffset(ordered[j])
+	})
+
+	var edits []findings.Edit
+	var applied []findings.Finding
+	for _, f := range ordered {
+		candidate := f.Suggestions[0].Edits
+		if !valid(candidate, len(src)) || overlapsAny(candidate, edits) {
+			continue
+		}
+		edits = append(edits, candidate...)
+		applied = append(applied, f)
+	}
+	if len(edits) == 0 {
+		return src, nil
+	}
+
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].Offset < edits[j].Offset })
+	out := make([]byte, 0, len(src))
+	last := 0
+	for _, e := range edits {
+		out = append(out, src[last:e.Offset]...)
+		out = append(out, e.NewText...)
+		last = e.Offset + e.Length
+	}
+	out = append(out, src[last:]...)
+	return out, applied
+}
+
+// firstOffset returns the smallest offset edited by the first suggestion of f.
+func firstOffset(f findings.Finding) int {
+	first := -1
+	for _, e := range f.Suggestions[0].Edits {
+		if first < 0 || e.Offset < first {
+			first = e.Offset
+		}
+	}
+	return first
+}
+
+// valid reports whether every edit lies within a source of length n and the
+// edits do not overlap one another.
+func valid(edits []findings.Edit, n int) bool {
+	for i, e := range edits {
+		if e.Offset < 0 || e.Length < 0 || e.Offset+e.Length > n {
+			return false
+		}
+		if overlapsAny([]findings.Edit{e}, edits[:i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// overlapsAny reports whether any edit in a overlaps any edit in b. Two
+// insertions at the same offset overlap since their order is ambiguous.
+func overlapsAny(a, b []findings.Edit) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x.Offset < y.Offset+y.Length && y.Offset < x.Offset+x.Length {
+				return true
+			}
+			if x.Offset == y.Offset && (x.Length == 0 || y.Length == 0) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// WriteFile replaces the contents of the file at path with data atomically.
+// The data is written to a temporary file in the same directory, which is
+// then renamed over path, so readers never observe a partial write. The
+// file's permissions are preserved.
+func WriteFile(path string, data []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
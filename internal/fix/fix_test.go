@@ -0,0 +1,71 @@
+// Copyright 2025 Sam Caldwell
+// SPDX-License-Identifier: MIT
+
+package fix
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/asymmetric-effort/mdlint/internal/findings"
+)
+
+// finding returns a finding suggesting the given edits.
+func finding(edits ...findings.Edit) findings.Finding {
+	return findings.Finding{Suggestions: []findings.SuggestedFix{{Label: "fix", Edits: edits}}}
+}
+
+func edit(offset, length int, text string) findings.Edit {
+	return findings.Edit{Offset: offset, Length: length, NewText: text}
+}
+
+func TestApply(t *testing.T) {
+	src := []byte("hello  world \n")
+	fs := []findings.Finding{
+		finding(edit(12, 1, "")),
+		{Message: "no suggestion"},
+		finding(edit(0, 5, "Hello")),
+		finding(edit(5, 2, " ")),
+		finding(edit(6, 1, "-")),                     // overlaps the previous edit
+		finding(edit(7, 0, "big "), edit(0, 0, "!")), // insertion at 0 clashes with the edit at 0
+		finding(edit(20, 1, "")),                     // out of range
+	}
+	out, applied := Apply(src, fs)
+	if string(out) != "Hello world\n" {
+		t.Fatalf("unexpected output %q", out)
+	}
+	if len(applied) != 3 {
+		t.Fatalf("expected 3 applied findings, got %d", len(applied))
+	}
+}
+
+func TestApplyNothing(t *testing.T) {
+	src := []byte("text\n")
+	out, applied := Apply(src, []findings.Finding{{Message: "x"}})
+	if string(out) != "text\n" || applied != nil {
+		t.Fatalf("unexpected result %q %v", out, applied)
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.md")
+	if err := os.WriteFile(path, []byte("old"), 0o640); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFile(path, []byte("new")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil || string(b) != "new" {
+		t.Fatalf("unexpected contents %q: %v", b, err)
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm() != 0o640 {
+		t.Fatalf("unexpected mode %v: %v", info.Mode(), err)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Fatalf("temporary file left behind: %v", entries)
+	}
+}
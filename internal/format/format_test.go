@@ -1,10 +1,11 @@
 // Copyright 2025 Sam Caldwell
 // SPDX-License-Identifier: MIT
 
-package format
+package format_test
 
 import (
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/asymmetric-effort/mdlint/internal/findings"
@@ -60,3 +61,37 @@ func assertGolden(t *testing.T, path string, got []byte) {
 		t.Fatalf("unexpected output:\nwant:\n%s\n---\ngot:\n%s", string(want), string(got))
 	}
 }
+
+func TestRangeFindings(t *testing.T) {
+	fs := []findings.Finding{
+		{File: "a.md", Line: 2, Column: 3, EndLine: 2, EndColumn: 9, Offset: 14, Length: 6, Rule: "MD1800", Severity: findings.Warning, Message: "ranged"},
+		{File: "a.md", Line: 1, Column: 1, Rule: "MD1000", Severity: findings.Warning, Message: "point"},
+	}
+	out, err := formatpkg.NewText(findings.Warning).Format(fs)
+	if err != nil {
+		t.Fatalf("format: %v", err)
+	}
+	if want := "a.md:1:1 MD1000[warning] point\na.md:2:3-2:9 MD1800[warning] ranged\n"; string(out) != want {
+		t.Fatalf("unexpected text output:\n%s", out)
+	}
+
+	out, err = formatpkg.NewJSON().Format(fs[:1])
+	if err != nil {
+		t.Fatalf("format: %v", err)
+	}
+	for _, field := range []string{`"end_line": 2`, `"end_column": 9`, `"offset": 14`, `"length": 6`} {
+		if !strings.Contains(string(out), field) {
+			t.Fatalf("JSON output missing %s:\n%s", field, out)
+		}
+	}
+
+	// A range at the start of the file keeps its zero offset.
+	start := findings.Finding{File: "a.md", Line: 1, Column: 1, EndLine: 1, EndColumn: 7, Length: 6, Rule: "MD1800", Severity: findings.Warning, Message: "ranged"}
+	out, err = formatpkg.NewJSON().Format([]findings.Finding{start, fs[1]})
+	if err != nil {
+		t.Fatalf("format: %v", err)
+	}
+	if strings.Count(string(out), `"offset": 0,`) != 1 || !strings.Contains(string(out), "\"offset\": 0,\n    \"length\": 6") {
+		t.Fatalf("JSON output misplaces the zero offset:\n%s", out)
+	}
+}
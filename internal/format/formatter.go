@@ -3,6 +3,7 @@
 package format
 
 import (
+	"fmt"
 	"sort"
 
 	"github.com/asymmetric-effort/mdlint/internal/findings"
@@ -13,6 +14,15 @@ type Formatter interface {
 	Format([]findings.Finding) ([]byte, error)
 }
 
+// Location renders the position of f as "file:line:column", followed by
+// "-endline:endcolumn" when the finding covers a range.
+func Location(f findings.Finding) string {
+	if !f.HasRange() {
+		return fmt.Sprintf("%s:%d:%d", f.File, f.Line, f.Column)
+	}
+	return fmt.Sprintf("%s:%d:%d-%d:%d", f.File, f.Line, f.Column, f.EndLine, f.EndColumn)
+}
+
 // sortFindings sorts the findings deterministically.
 func sortFindings(fs []findings.Finding) {
 	sort.Slice(fs, func(i, j int) bool {
@@ -32,7 +32,7 @@ func (t *Text) Format(fs []findings.Finding) ([]byte, error) {
 		if i > 0 {
 			buf.WriteByte('\n')
 		}
-		fmt.Fprintf(&buf, "%s:%d:%d %s[%s] %s", f.File, f.Line, f.Column, f.Rule, f.Severity.String(), f.Message)
+		fmt.Fprintf(&buf, "%s %s[%s] %s", Location(f), f.Rule, f.Severity.String(), f.Message)
 	}
 	if buf.Len() > 0 {
 		buf.WriteByte('\n')
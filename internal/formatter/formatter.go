@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/asymmetric-effort/mdlint/internal/findings"
+	formatpkg "github.com/asymmetric-effort/mdlint/internal/format"
 )
 
 // Format returns formatted findings.
@@ -22,7 +23,7 @@ func Format(fs []findings.Finding, format string) (string, error) {
 	case "text":
 		var sb strings.Builder
 		for _, f := range fs {
-			fmt.Fprintf(&sb, "%s:%d:%d %s %s\n", f.File, f.Line, f.Column, f.Rule, f.Message)
+			fmt.Fprintf(&sb, "%s %s %s\n", formatpkg.Location(f), f.Rule, f.Message)
 		}
 		return sb.String(), nil
 	default:
@@ -0,0 +1,6 @@
+// Copyright 2025 Sam Caldwell
+// SPDX-License-Identifier: MIT
+
+// Package glob matches slash-separated paths against gitignore-style glob
+// patterns supporting "**", negation and directory anchoring.
+package glob
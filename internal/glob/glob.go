@@ -0,0 +1,132 @@
+// Copyright 2025 Sam Caldwell
+// SPDX-License-Identifier: MIT
+
+package glob
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Match reports whether the slash-separated relative path name matches
+// pattern. Within a path segment "*", "?" and "[...]" behave as in
+// path.Match, and a "**" segment matches zero or more segments. A pattern
+// without a slash, other than a trailing one, matches at any depth, so "*.md"
+// selects Markdown files in every directory; a leading slash anchors a pattern
+// to the root instead. A pattern ending in a slash only matches directories.
+// A pattern matching a directory also matches everything below it.
+//
+// Malformed patterns match nothing; use Validate to report them.
+func Match(pattern, name string) bool {
+	return match(pattern, name, false)
+}
+
+// MatchDir is like Match for a directory named name.
+func MatchDir(pattern, name string) bool {
+	return match(pattern, name, true)
+}
+
+// List is an ordered list of patterns in which a pattern prefixed with "!"
+// re-includes paths matched by earlier patterns. The last matching pattern
+// decides, as in .gitignore files.
+type List []string
+
+// Match reports whether the file name is matched by the list.
+func (l List) Match(name string) bool {
+	return l.match(name, false)
+}
+
+// MatchDir reports whether the directory name is matched by the list.
+func (l List) MatchDir(name string) bool {
+	return l.match(name, true)
+}
+
+func (l List) match(name string, dir bool) bool {
+	matched := false
+	for _, p := range l {
+		negated := strings.HasPrefix(p, "!")
+		if negated == matched && match(strings.TrimPrefix(p, "!"), name, dir) {
+			matched = !negated
+		}
+	}
+	return matched
+}
+
+// Prune reports whether the directory name and everything below it is
+// matched by the list, so that a walker excluding the list may skip it. A
+// negated pattern may re-include paths below a matched directory, so lists
+// with negations never prune.
+func (l List) Prune(name string) bool {
+	for _, p := range l {
+		if strings.HasPrefix(p, "!") {
+			return false
+		}
+	}
+	return l.MatchDir(name)
+}
+
+// Validate reports an error when pattern is malformed.
+func Validate(pattern string) error {
+	p := strings.Trim(strings.TrimPrefix(pattern, "!"), "/")
+	if p == "" {
+		return fmt.Errorf("empty glob pattern %q", pattern)
+	}
+	for _, seg := range strings.Split(p, "/") {
+		if _, err := path.Match(seg, ""); err != nil {
+			return fmt.Errorf("invalid glob pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// match reports whether pattern matches name, a directory when dir is true,
+// or one of its parent directories.
+func match(pattern, name string, dir bool) bool {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	if strings.HasPrefix(pattern, "/") {
+		pattern = pattern[1:]
+	} else if !strings.Contains(pattern, "/") {
+		pattern = "**/" + pattern
+	}
+	if pattern == "" {
+		return false
+	}
+	ps := strings.Split(pattern, "/")
+	ns := strings.Split(strings.Trim(name, "/"), "/")
+	for i := 1; i <= len(ns); i++ {
+		isDir := dir || i < len(ns)
+		if (isDir || !dirOnly) && matchSegments(ps, ns[:i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments matches path segments against pattern segments, letting a
+// "**" segment consume any number of path segments.
+func matchSegments(ps, ns []string) bool {
+	for len(ps) > 0 {
+		if ps[0] == "**" {
+			ps = ps[1:]
+			if len(ps) == 0 {
+				return true
+			}
+			for i := 0; i <= len(ns); i++ {
+				if matchSegments(ps, ns[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(ns) == 0 {
+			return false
+		}
+		if ok, err := path.Match(ps[0], ns[0]); err != nil || !ok {
+			return false
+		}
+		ps, ns = ps[1:], ns[1:]
+	}
+	return len(ns) == 0
+}
@@ -0,0 +1,77 @@
+// Copyright 2025 Sam Caldwell
+// SPDX-License-Identifier: MIT
+
+package glob
+
+import "testing"
+
+// TestMatch covers globstar, base name, anchored and directory patterns.
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"*.md", "README.md", true},
+		{"*.md", "docs/guide/intro.md", true},
+		{"*.md", "docs/notes.txt", false},
+		{"docs/*.md", "docs/intro.md", true},
+		{"docs/*.md", "docs/guide/intro.md", false},
+		{"docs/**", "docs/guide/intro.md", true},
+		{"docs/**", "other/docs/intro.md", false},
+		{"docs/**/*.md", "docs/intro.md", true},
+		{"docs/**/*.md", "docs/a/b/intro.md", true},
+		{"**/vendor/**", "a/vendor/b/c.md", true},
+		{"/README.md", "README.md", true},
+		{"/README.md", "docs/README.md", false},
+		{"vendor", "vendor/pkg/README.md", true},
+		{"vendor", "docs/vendor/x.md", true},
+		{"build/", "build", false},
+		{"build/", "build/out.md", true},
+		{"docs/a?.md", "docs/ab.md", true},
+		{"docs/[", "docs/[", false},
+	}
+	for _, tc := range tests {
+		if got := Match(tc.pattern, tc.name); got != tc.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tc.pattern, tc.name, got, tc.want)
+		}
+	}
+	if !MatchDir("build/", "build") {
+		t.Errorf("MatchDir(%q, %q) = false, want true", "build/", "build")
+	}
+}
+
+// TestList verifies that negated patterns re-include paths and that the last
+// matching pattern decides.
+func TestList(t *testing.T) {
+	l := List{"docs/**", "!docs/keep/**", "docs/keep/draft.md"}
+	for name, want := range map[string]bool{
+		"docs/a.md":          true,
+		"docs/keep/b.md":     false,
+		"docs/keep/draft.md": true,
+		"README.md":          false,
+	} {
+		if got := l.Match(name); got != want {
+			t.Errorf("Match(%q) = %v, want %v", name, got, want)
+		}
+	}
+	if l.Prune("docs") {
+		t.Error("list with negations must not prune")
+	}
+	if !(List{"docs/**"}).Prune("docs/sub") || (List{"docs/**"}).Prune("src") {
+		t.Error("unexpected prune result")
+	}
+}
+
+// TestValidate reports malformed patterns.
+func TestValidate(t *testing.T) {
+	for _, p := range []string{"docs/**", "!*.md", "/a/[bc]/"} {
+		if err := Validate(p); err != nil {
+			t.Errorf("Validate(%q): %v", p, err)
+		}
+	}
+	for _, p := range []string{"", "!", "docs/[a"} {
+		if Validate(p) == nil {
+			t.Errorf("Validate(%q): expected error", p)
+		}
+	}
+}
@@ -33,17 +33,6 @@ func TestFrontMatterRange(t *testing.T) {
 	}
 }
 
-func TestCodeBlockRanges(t *testing.T) {
-	src := readFixture(t, "basic.md")
-	ranges := CodeBlockRanges(src)
-	if len(ranges) != 1 {
-		t.Fatalf("expected one code block, got %d", len(ranges))
-	}
-	if ranges[0].StartLine != 9 || ranges[0].EndLine != 11 {
-		t.Fatalf("unexpected range: %#v", ranges[0])
-	}
-}
-
 func TestParserSupportsExtensions(t *testing.T) {
 	src := readFixture(t, "basic.md")
 	md := Parser()
@@ -56,4 +45,3 @@ func TestParserSupportsExtensions(t *testing.T) {
 		t.Fatalf("expected parsed document")
 	}
 }
-
@@ -2,12 +2,7 @@
 
 package markdown
 
-import (
-	"bytes"
-
-	"github.com/yuin/goldmark/ast"
-	"github.com/yuin/goldmark/text"
-)
+import "bytes"
 
 // Range represents an inclusive line range within a Markdown document.
 type Range struct {
@@ -15,51 +10,6 @@ type Range struct {
 	EndLine   int // 1-based line number where the range ends
 }
 
-// CodeBlockRanges returns line ranges for all code blocks in the provided source.
-// Ranges include fenced and indented code blocks.
-func CodeBlockRanges(src []byte) []Range {
-	md := Parser()
-	reader := text.NewReader(src)
-	doc := md.Parser().Parse(reader)
-
-	var ranges []Range
-	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
-		if !entering {
-			return ast.WalkContinue, nil
-		}
-		switch n := n.(type) {
-		case *ast.FencedCodeBlock:
-			lines := n.Lines()
-			if lines == nil || lines.Len() == 0 {
-				return ast.WalkContinue, nil
-			}
-			first := lines.At(0)
-			last := lines.At(lines.Len() - 1)
-			start := lineNumber(src, first.Start) - 1
-			if start < 1 {
-				start = 1
-			}
-			end := lineNumber(src, last.Stop)
-			ranges = append(ranges, Range{StartLine: start, EndLine: end})
-			return ast.WalkSkipChildren, nil
-		case *ast.CodeBlock:
-			lines := n.Lines()
-			if lines == nil || lines.Len() == 0 {
-				return ast.WalkContinue, nil
-			}
-			first := lines.At(0)
-			last := lines.At(lines.Len() - 1)
-			ranges = append(ranges, Range{
-				StartLine: lineNumber(src, first.Start),
-				EndLine:   lineNumber(src, last.Stop),
-			})
-			return ast.WalkSkipChildren, nil
-		}
-		return ast.WalkContinue, nil
-	})
-	return ranges
-}
-
 // FrontMatterRange returns the line range for a leading YAML front-matter
 // section. If no front-matter is present, ok will be false.
 func FrontMatterRange(src []byte) (rng Range, ok bool) {
@@ -77,8 +27,3 @@ func FrontMatterRange(src []byte) (rng Range, ok bool) {
 	}
 	return Range{}, false
 }
-
-// lineNumber converts a byte offset into a 1-based line number.
-func lineNumber(src []byte, pos int) int {
-	return bytes.Count(src[:pos], []byte("\n")) + 1
-}
@@ -0,0 +1,6 @@
+// Copyright 2025 Sam Caldwell
+// SPDX-License-Identifier: MIT
+
+// Package parser parses Markdown files once into a Document shared by every
+// rule.
+package parser
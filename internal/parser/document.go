@@ -0,0 +1,149 @@
+// Copyright (c) 2024 Asymmetric Effort
+
+package parser
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+
+	"github.com/yuin/goldmark/ast"
+	gparser "github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+
+	"github.com/asymmetric-effort/mdlint/internal/markdown"
+)
+
+// Document is a Markdown file parsed once by the engine and shared by every
+// rule applied to it.
+type Document struct {
+	// Path is the file the document was read from.
+	Path string
+	// Source holds the raw file contents.
+	Source []byte
+	// Root is the goldmark AST of Source built by markdown.Parser.
+	Root ast.Node
+	// FrontMatter is the line range of leading YAML front matter. It is only
+	// meaningful when HasFrontMatter is true.
+	FrontMatter markdown.Range
+	// HasFrontMatter reports whether the document starts with front matter.
+	HasFrontMatter bool
+	// CodeBlocks lists the line ranges of fenced and indented code blocks.
+	CodeBlocks []markdown.Range
+
+	// context is the goldmark parser context, which holds link reference
+	// definitions.
+	context gparser.Context
+	// lineStarts holds the byte offset at which each line begins.
+	lineStarts []int
+	// nodes holds the node facades, built on first use.
+	nodes     *nodeIndex
+	nodesOnce sync.Once
+	// extents caches the byte range of each AST node by node.
+	extents sync.Map
+	// prose holds the outline, definition, segment, word and sentence indexes,
+	// built on first use.
+	prose     *proseIndex
+	proseOnce sync.Once
+}
+
+// Parse builds a Document from src. The path is recorded for reporting only.
+func Parse(path string, src []byte) *Document {
+	pc := gparser.NewContext()
+	root := markdown.Parser().Parser().Parse(text.NewReader(src), gparser.WithContext(pc))
+	doc := &Document{
+		Path:       path,
+		Source:     src,
+		Root:       root,
+		context:    pc,
+		lineStarts: lineStarts(src),
+	}
+	doc.FrontMatter, doc.HasFrontMatter = markdown.FrontMatterRange(src)
+	doc.CodeBlocks = doc.codeBlocks()
+	return doc
+}
+
+// lineStarts returns the byte offset of the first byte of every line in src.
+func lineStarts(src []byte) []int {
+	starts := []int{0}
+	for i, b := range src {
+		if b == '\n' && i+1 < len(src) {
+			starts = append(starts, i+1)
+		}
+	}
+	return starts
+}
+
+// LineCount returns the number of lines in the document. A trailing newline
+// does not start a new line.
+func (d *Document) LineCount() int {
+	if len(d.Source) == 0 {
+		return 0
+	}
+	return len(d.lineStarts)
+}
+
+// Line returns the contents of the 1-based line n without its line ending
+// newline. It returns nil when n is out of range.
+func (d *Document) Line(n int) []byte {
+	if n < 1 || n > d.LineCount() {
+		return nil
+	}
+	start := d.lineStarts[n-1]
+	end := len(d.Source)
+	if n < len(d.lineStarts) {
+		end = d.lineStarts[n]
+	}
+	return bytes.TrimSuffix(d.Source[start:end], []byte("\n"))
+}
+
+// LineOffset returns the byte offset at which the 1-based line n starts.
+func (d *Document) LineOffset(n int) int {
+	if n < 1 {
+		return 0
+	}
+	if n > len(d.lineStarts) {
+		return len(d.Source)
+	}
+	return d.lineStarts[n-1]
+}
+
+// Position converts a byte offset into a 1-based line and column. Columns
+// count bytes from the start of the line.
+func (d *Document) Position(offset int) (line, column int) {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > len(d.Source) {
+		offset = len(d.Source)
+	}
+	i := sort.Search(len(d.lineStarts), func(i int) bool { return d.lineStarts[i] > offset }) - 1
+	return i + 1, offset - d.lineStarts[i] + 1
+}
+
+// InCodeBlock reports whether the 1-based line n lies within a code block,
+// including the fences of fenced blocks.
+func (d *Document) InCodeBlock(n int) bool {
+	for _, r := range d.CodeBlocks {
+		if n >= r.StartLine && n <= r.EndLine {
+			return true
+		}
+	}
+	return false
+}
+
+// InFrontMatter reports whether the 1-based line n lies within front matter.
+func (d *Document) InFrontMatter(n int) bool {
+	return d.HasFrontMatter && n >= d.FrontMatter.StartLine && n <= d.FrontMatter.EndLine
+}
+
+// FrontMatterSource returns the YAML between the front matter delimiters, or
+// nil when the document has no front matter.
+func (d *Document) FrontMatterSource() []byte {
+	if !d.HasFrontMatter || d.FrontMatter.EndLine-d.FrontMatter.StartLine < 2 {
+		return nil
+	}
+	start := d.LineOffset(d.FrontMatter.StartLine + 1)
+	end := d.LineOffset(d.FrontMatter.EndLine)
+	return d.Source[start:end]
+}
@@ -0,0 +1,63 @@
+// Copyright 2025 Sam Caldwell
+// SPDX-License-Identifier: MIT
+
+package parser
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	src := []byte("---\ntitle: x\n---\n\n# Heading\n\n```go\ncode\n```\ntail")
+	doc := Parse("a.md", src)
+	if doc.Root == nil {
+		t.Fatalf("expected AST root")
+	}
+	if !doc.HasFrontMatter || doc.FrontMatter.EndLine != 3 {
+		t.Fatalf("unexpected front matter: %+v", doc.FrontMatter)
+	}
+	if len(doc.CodeBlocks) != 1 || doc.CodeBlocks[0].StartLine != 7 || doc.CodeBlocks[0].EndLine != 9 {
+		t.Fatalf("unexpected code blocks: %+v", doc.CodeBlocks)
+	}
+	if !doc.InCodeBlock(8) || doc.InCodeBlock(10) {
+		t.Fatalf("unexpected code block membership")
+	}
+	if !doc.InFrontMatter(2) || doc.InFrontMatter(4) {
+		t.Fatalf("unexpected front matter membership")
+	}
+}
+
+func TestLines(t *testing.T) {
+	doc := Parse("", []byte("one\ntwo\n\nfour\n"))
+	if n := doc.LineCount(); n != 4 {
+		t.Fatalf("expected 4 lines, got %d", n)
+	}
+	want := []string{"one", "two", "", "four"}
+	for i, w := range want {
+		if got := string(doc.Line(i + 1)); got != w {
+			t.Fatalf("line %d: got %q want %q", i+1, got, w)
+		}
+	}
+	if doc.Line(5) != nil || doc.Line(0) != nil {
+		t.Fatalf("expected nil for out of range lines")
+	}
+	if off := doc.LineOffset(4); off != 9 {
+		t.Fatalf("expected offset 9, got %d", off)
+	}
+}
+
+func TestPosition(t *testing.T) {
+	doc := Parse("", []byte("ab\ncd\n"))
+	tests := []struct{ offset, line, col int }{
+		{0, 1, 1},
+		{1, 1, 2},
+		{2, 1, 3},
+		{3, 2, 1},
+		{4, 2, 2},
+		{6, 2, 4},
+	}
+	for _, tt := range tests {
+		line, col := doc.Position(tt.offset)
+		if line != tt.line || col != tt.col {
+			t.Fatalf("offset %d: got %d:%d want %d:%d", tt.offset, line, col, tt.line, tt.col)
+		}
+	}
+}
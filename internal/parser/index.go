@@ -0,0 +1,359 @@
+// Copyright (c) 2024 Asymmetric Effort
+
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/util"
+)
+
+// HeadingStyle identifies how a heading is written.
+type HeadingStyle string
+
+// Heading styles reported by Outline.
+const (
+	// StyleATX is a heading opened by '#' characters.
+	StyleATX HeadingStyle = "atx"
+	// StyleATXClosed is an ATX heading also closed by '#' characters.
+	StyleATXClosed HeadingStyle = "atx_closed"
+	// StyleSetext is a heading underlined with '=' or '-'.
+	StyleSetext HeadingStyle = "setext"
+)
+
+// OutlineEntry is a heading within the document outline.
+type OutlineEntry struct {
+	Heading
+	// Anchor is the GitHub-style fragment identifier of the heading, made
+	// unique within the document.
+	Anchor string
+	// Style is the syntax used to write the heading.
+	Style HeadingStyle
+	// Parent is the index of the enclosing heading in the outline, or -1
+	// for top-level headings.
+	Parent int
+}
+
+// Definition is a link reference definition such as "[label]: /url".
+type Definition struct {
+	Span
+	// Label is the normalized reference label.
+	Label string
+	// Destination is the link destination.
+	Destination string
+	// Title is the optional link title.
+	Title string
+}
+
+// Word is a single Unicode word token taken from prose.
+type Word struct {
+	Span
+	// Text is the word as written in the source.
+	Text string
+	// Scope holds the scopes of the segment containing the word.
+	Scope Scope
+}
+
+// Sentence is a sentence of prose within a single block.
+type Sentence struct {
+	Span
+	// Text is the plain text of the sentence.
+	Text string
+	// Scope holds the scopes of the block containing the sentence.
+	Scope Scope
+}
+
+// proseIndex holds the prose-derived indexes of a document.
+type proseIndex struct {
+	outline     []OutlineEntry
+	definitions []Definition
+	segments    []Segment
+	words       []Word
+	sentences   []Sentence
+}
+
+// Outline returns the heading outline in source order.
+func (d *Document) Outline() []OutlineEntry { return d.indexProse().outline }
+
+// Definitions returns the link reference definitions in source order.
+func (d *Document) Definitions() []Definition { return d.indexProse().definitions }
+
+// Words returns the word tokens of the document's prose in source order.
+// Code, raw HTML and autolinks are excluded.
+func (d *Document) Words() []Word { return d.indexProse().words }
+
+// Sentences returns the sentences of the document's prose in source order.
+// Sentences never span blocks such as paragraphs or headings.
+func (d *Document) Sentences() []Sentence { return d.indexProse().sentences }
+
+// indexProse builds the prose indexes on first use.
+func (d *Document) indexProse() *proseIndex {
+	d.proseOnce.Do(func() {
+		idx := &proseIndex{
+			outline:     d.buildOutline(),
+			definitions: d.buildDefinitions(),
+		}
+		for _, blk := range d.proseBlocks() {
+			for _, r := range blk.runs {
+				if len(r.text) == 0 {
+					continue
+				}
+				idx.segments = append(idx.segments, Segment{
+					Span:  d.Span(r.start, r.start+len(r.text)),
+					Text:  string(r.text),
+					Scope: blk.scope,
+				})
+				idx.words = append(idx.words, d.tokenize(r, blk.scope)...)
+			}
+			idx.sentences = append(idx.sentences, d.split(blk.runs, blk.scope)...)
+		}
+		d.prose = idx
+	})
+	return d.prose
+}
+
+func (d *Document) buildOutline() []OutlineEntry {
+	var outline []OutlineEntry
+	seen := map[string]int{}
+	for _, h := range d.Headings() {
+		e := OutlineEntry{Heading: h, Style: StyleATX, Parent: -1}
+		if h.Setext {
+			e.Style = StyleSetext
+		} else if lines := h.Node.Lines(); lines.Len() > 0 {
+			// The heading's text excludes the closing sequence, if any.
+			if stop := lines.At(lines.Len() - 1).Stop; stop < h.End.Offset &&
+				bytes.IndexByte(d.Source[stop:h.End.Offset], '#') >= 0 {
+				e.Style = StyleATXClosed
+			}
+		}
+		slug := Slug(h.Text)
+		e.Anchor = slug
+		if n := seen[slug]; n > 0 {
+			e.Anchor = fmt.Sprintf("%s-%d", slug, n)
+		}
+		seen[slug]++
+		for p := len(outline) - 1; p >= 0; p-- {
+			if outline[p].Level < h.Level {
+				e.Parent = p
+				break
+			}
+		}
+		outline = append(outline, e)
+	}
+	return outline
+}
+
+// Slug converts heading text into a GitHub-style anchor: lower case, with
+// punctuation removed and spaces replaced by hyphens.
+func Slug(s string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r) || r == '-' || r == '_':
+			sb.WriteRune(r)
+		case r == ' ':
+			sb.WriteByte('-')
+		}
+	}
+	return sb.String()
+}
+
+// definitionRE matches the first line of a link reference definition.
+var definitionRE = regexp.MustCompile(`^ {0,3}\[((?:[^\]\\]|\\.)+)\]:`)
+
+func (d *Document) buildDefinitions() []Definition {
+	var defs []Definition
+	for n := 1; n <= d.LineCount(); n++ {
+		if d.InCodeBlock(n) || d.InFrontMatter(n) {
+			continue
+		}
+		line := d.Line(n)
+		m := definitionRE.FindSubmatchIndex(line)
+		if m == nil {
+			continue
+		}
+		label := util.ToLinkReference(line[m[2]:m[3]])
+		ref, ok := d.context.Reference(label)
+		if !ok {
+			continue
+		}
+		off := d.LineOffset(n)
+		start, end := d.trim(off, off+len(line))
+		defs = append(defs, Definition{
+			Span:        d.Span(start, end),
+			Label:       label,
+			Destination: string(ref.Destination()),
+			Title:       string(ref.Title()),
+		})
+	}
+	return defs
+}
+
+// run is a contiguous piece of prose text taken from one Text node.
+type run struct {
+	text  []byte
+	start int
+	// breakAfter reports whether the run is followed by a line break or
+	// excluded inline content, which separates words.
+	breakAfter bool
+}
+
+// runs returns the prose text runs below the block n, skipping code spans,
+// raw HTML and autolinks, including bare URLs recognized by GFM.
+func (d *Document) runs(n ast.Node) []run {
+	var runs []run
+	_ = ast.Walk(n, func(c ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch c := c.(type) {
+		case *ast.CodeSpan, *ast.RawHTML, *ast.AutoLink:
+			if len(runs) > 0 {
+				runs[len(runs)-1].breakAfter = true
+			}
+			return ast.WalkSkipChildren, nil
+		case *ast.Text:
+			// goldmark splits text at inline trigger characters; join
+			// pieces that are adjacent in the source.
+			if k := len(runs) - 1; k >= 0 && !runs[k].breakAfter && runs[k].start+len(runs[k].text) == c.Segment.Start {
+				runs[k].text = d.Source[runs[k].start:c.Segment.Stop]
+				runs[k].breakAfter = c.SoftLineBreak() || c.HardLineBreak()
+				return ast.WalkContinue, nil
+			}
+			runs = append(runs, run{
+				text:       c.Segment.Value(d.Source),
+				start:      c.Segment.Start,
+				breakAfter: c.SoftLineBreak() || c.HardLineBreak(),
+			})
+		}
+		return ast.WalkContinue, nil
+	})
+	return runs
+}
+
+// tokenize splits a run into Unicode word tokens. Apostrophes between
+// letters are kept so that contractions form a single word.
+func (d *Document) tokenize(r run, scope Scope) []Word {
+	var words []Word
+	start := -1
+	flush := func(end int) {
+		if start >= 0 {
+			words = append(words, Word{
+				Span:  d.Span(r.start+start, r.start+end),
+				Text:  string(r.text[start:end]),
+				Scope: scope,
+			})
+			start = -1
+		}
+	}
+	for i := 0; i < len(r.text); {
+		c, size := utf8.DecodeRune(r.text[i:])
+		switch {
+		case isWordRune(c):
+			if start < 0 {
+				start = i
+			}
+		case (c == '\'' || c == '’') && start >= 0 && i+size < len(r.text):
+			next, _ := utf8.DecodeRune(r.text[i+size:])
+			if !unicode.IsLetter(next) {
+				flush(i)
+			}
+		default:
+			flush(i)
+		}
+		i += size
+	}
+	flush(len(r.text))
+	return words
+}
+
+func isWordRune(c rune) bool {
+	return unicode.IsLetter(c) || unicode.IsDigit(c) || unicode.IsMark(c)
+}
+
+// abbreviations lists lower-case abbreviations whose trailing period does not
+// end a sentence.
+var abbreviations = map[string]bool{
+	"e.g": true, "i.e": true, "vs": true, "cf": true,
+	"mr": true, "mrs": true, "ms": true, "dr": true, "st": true, "no": true,
+}
+
+// split divides the runs of one block into sentences.
+func (d *Document) split(runs []run, scope Scope) []Sentence {
+	// Flatten the runs, remembering the source offset of every byte. Bytes
+	// inserted between runs have no source offset.
+	var text []byte
+	var offs []int
+	for _, r := range runs {
+		for i, b := range r.text {
+			text = append(text, b)
+			offs = append(offs, r.start+i)
+		}
+		if r.breakAfter {
+			text = append(text, ' ')
+			offs = append(offs, -1)
+		}
+	}
+
+	var sentences []Sentence
+	emit := func(from, to int) {
+		for from < to && (isSpace(text[from]) || offs[from] < 0) {
+			from++
+		}
+		for to > from && (isSpace(text[to-1]) || offs[to-1] < 0) {
+			to--
+		}
+		if from == to {
+			return
+		}
+		sentences = append(sentences, Sentence{
+			Span:  d.Span(offs[from], offs[to-1]+1),
+			Text:  strings.Join(strings.Fields(string(text[from:to])), " "),
+			Scope: scope,
+		})
+	}
+
+	begin := 0
+	for i := 0; i < len(text); i++ {
+		if text[i] != '.' && text[i] != '!' && text[i] != '?' {
+			continue
+		}
+		j := i
+		for j < len(text) && strings.IndexByte(".!?\"')]”’", text[j]) >= 0 {
+			j++
+		}
+		if j < len(text) && !isSpace(text[j]) {
+			i = j - 1
+			continue
+		}
+		if text[i] == '.' && abbreviations[lastToken(text[begin:i])] {
+			i = j - 1
+			continue
+		}
+		emit(begin, j)
+		begin = j
+		i = j - 1
+	}
+	emit(begin, len(text))
+	return sentences
+}
+
+// lastToken returns the lower-cased token preceding a period, keeping inner
+// periods so that "e.g" is recognized.
+func lastToken(b []byte) string {
+	i := len(b)
+	for i > 0 && (isWordRuneByte(b[i-1]) || b[i-1] == '.') {
+		i--
+	}
+	return strings.ToLower(string(b[i:]))
+}
+
+func isWordRuneByte(b byte) bool {
+	return b >= utf8.RuneSelf || unicode.IsLetter(rune(b)) || unicode.IsDigit(rune(b))
+}
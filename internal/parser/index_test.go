@@ -0,0 +1,142 @@
+// Copyright 2025 Sam Caldwell
+// SPDX-License-Identifier: MIT
+
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+const prose = `# Intro
+
+## Usage ##
+
+### Usage
+
+Setup
+=====
+
+## C#
+
+See [the docs][Docs], [docs][] and [Docs]. Don't run ` + "`rm -rf`" + `, e.g. on prod!
+Visit <https://x.example> now. Dr. Who approves? Yes.
+
+[docs]: https://example.com/docs "Docs"
+
+` + "```" + `
+[code]: /not-a-definition
+` + "```" + `
+
+| Cell one | two |
+| -------- | --- |
+`
+
+func TestOutline(t *testing.T) {
+	doc := Parse("", []byte(prose))
+	out := doc.Outline()
+	if len(out) != 5 {
+		t.Fatalf("expected 5 outline entries, got %d", len(out))
+	}
+	tests := []struct {
+		anchor string
+		style  HeadingStyle
+		parent int
+	}{
+		{"intro", StyleATX, -1},
+		{"usage", StyleATXClosed, 0},
+		{"usage-1", StyleATX, 1},
+		{"setup", StyleSetext, -1},
+		{"c", StyleATX, 3},
+	}
+	for i, tt := range tests {
+		if e := out[i]; e.Anchor != tt.anchor || e.Style != tt.style || e.Parent != tt.parent {
+			t.Fatalf("entry %d: got %q %q %d", i, e.Anchor, e.Style, e.Parent)
+		}
+	}
+}
+
+func TestSlug(t *testing.T) {
+	tests := map[string]string{
+		"Hello, World!":     "hello-world",
+		"Foo_bar -- baz":    "foo_bar----baz",
+		"Ünïcödé Heading 2": "ünïcödé-heading-2",
+	}
+	for in, want := range tests {
+		if got := Slug(in); got != want {
+			t.Fatalf("Slug(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestDefinitions(t *testing.T) {
+	doc := Parse("", []byte(prose))
+	defs := doc.Definitions()
+	if len(defs) != 1 {
+		t.Fatalf("expected 1 definition, got %+v", defs)
+	}
+	d := defs[0]
+	if d.Label != "docs" || d.Destination != "https://example.com/docs" || d.Title != "Docs" ||
+		d.Start.Line != 15 || d.Start.Column != 1 {
+		t.Fatalf("unexpected definition: %+v", d)
+	}
+
+	var refs []string
+	for _, l := range doc.Links() {
+		refs = append(refs, l.Reference)
+	}
+	if want := []string{"docs", "docs", "docs", ""}; !reflect.DeepEqual(refs, want) {
+		t.Fatalf("unexpected references: %q", refs)
+	}
+}
+
+func TestWords(t *testing.T) {
+	doc := Parse("", []byte(prose))
+	var words []string
+	for _, w := range doc.Words() {
+		if w.Start.Line == 12 {
+			words = append(words, w.Text)
+		}
+		if got := source(doc, w.Span); got != w.Text {
+			t.Fatalf("word %q covers %q", w.Text, got)
+		}
+	}
+	want := []string{"See", "the", "docs", "docs", "and", "Docs", "Don't", "run", "e", "g", "on", "prod"}
+	if !reflect.DeepEqual(words, want) {
+		t.Fatalf("unexpected words: %q", words)
+	}
+	for _, w := range doc.Words() {
+		if w.Text == "rm" || w.Text == "https" || w.Text == "code" {
+			t.Fatalf("unexpected word from excluded content: %+v", w)
+		}
+	}
+	last := doc.Words()[len(doc.Words())-1]
+	if last.Text != "two" || last.Start.Line != 21 {
+		t.Fatalf("unexpected table word: %+v", last)
+	}
+}
+
+func TestSentences(t *testing.T) {
+	doc := Parse("", []byte(prose))
+	var got []string
+	for _, s := range doc.Sentences() {
+		if s.Start.Line >= 12 && s.Start.Line <= 13 {
+			got = append(got, s.Text)
+		}
+	}
+	want := []string{
+		"See the docs, docs and Docs.",
+		"Don't run , e.g. on prod!",
+		"Visit now.",
+		"Dr. Who approves?",
+		"Yes.",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected sentences: %q", got)
+	}
+	for _, s := range doc.Sentences() {
+		if s.Text == "Dr. Who approves?" && (source(doc, s.Span) != s.Text || s.Start.Column != 32) {
+			t.Fatalf("unexpected sentence span: %+v", s)
+		}
+	}
+}
@@ -0,0 +1,754 @@
+// Copyright (c) 2024 Asymmetric Effort
+
+package parser
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/util"
+)
+
+// Heading is an ATX or setext heading.
+type Heading struct {
+	Span
+	// Level is the heading level from 1 to 6.
+	Level int
+	// Text is the plain text of the heading.
+	Text string
+	// Setext reports whether the heading uses an underline instead of '#'.
+	Setext bool
+	// Node is the underlying AST node.
+	Node *ast.Heading
+}
+
+// Link is an inline, reference or autolink.
+type Link struct {
+	Span
+	// Text is the plain text of the link label.
+	Text string
+	// Destination is the resolved link destination.
+	Destination string
+	// Title is the optional link title.
+	Title string
+	// Reference is the normalized label of the reference definition used by
+	// a reference link. It is empty for inline links and autolinks.
+	Reference string
+	// Autolink reports whether the link is an autolink such as <https://x>.
+	Autolink bool
+	// Node is the underlying AST node.
+	Node ast.Node
+}
+
+// Image is an inline or reference image.
+type Image struct {
+	Span
+	// Alt is the plain text of the image description.
+	Alt string
+	// Destination is the image source.
+	Destination string
+	// Title is the optional image title.
+	Title string
+	// Node is the underlying AST node.
+	Node *ast.Image
+}
+
+// CodeSpan is inline code delimited by backticks.
+type CodeSpan struct {
+	Span
+	// Text is the code without its delimiters.
+	Text string
+	// Node is the underlying AST node.
+	Node *ast.CodeSpan
+}
+
+// FencedBlock is a fenced code block. Its span runs from the opening fence
+// to the end of the closing fence.
+type FencedBlock struct {
+	Span
+	// Fence is the span of the opening fence marker, e.g. "```".
+	Fence Span
+	// Info is the full info string following the opening fence.
+	Info string
+	// Language is the first word of the info string.
+	Language string
+	// Content covers the lines between the fences.
+	Content Span
+	// Closed reports whether the block has a closing fence.
+	Closed bool
+	// Node is the underlying AST node.
+	Node *ast.FencedCodeBlock
+}
+
+// Table is a GFM table.
+type Table struct {
+	Span
+	// Rows holds the header row followed by the body rows.
+	Rows []TableRow
+	// Node is the underlying AST node.
+	Node *east.Table
+}
+
+// TableRow is a single table row.
+type TableRow struct {
+	Span
+	// Header reports whether the row is the table header.
+	Header bool
+	// Cells holds the span of each cell's content.
+	Cells []Span
+}
+
+// ListItem is an item of an ordered or unordered list.
+type ListItem struct {
+	Span
+	// Marker is the list marker, e.g. "-" or "1.".
+	Marker string
+	// Ordered reports whether the item belongs to an ordered list.
+	Ordered bool
+	// Node is the underlying AST node.
+	Node *ast.ListItem
+}
+
+// Paragraph is a paragraph or the text block of a tight list item.
+type Paragraph struct {
+	Span
+	// Node is the underlying *ast.Paragraph or *ast.TextBlock.
+	Node ast.Node
+}
+
+// Comment is an HTML comment such as "<!-- note -->" appearing as block or
+// inline HTML. Comments inside code are not reported.
+type Comment struct {
+	Span
+	// Text is the trimmed text between "<!--" and "-->".
+	Text string
+}
+
+// nodeIndex holds the facades of a document in source order.
+type nodeIndex struct {
+	headings   []Heading
+	links      []Link
+	images     []Image
+	codeSpans  []CodeSpan
+	fencedCode []FencedBlock
+	tables     []Table
+	listItems  []ListItem
+	paragraphs []Paragraph
+	comments   []Comment
+}
+
+// Headings returns all headings in source order.
+func (d *Document) Headings() []Heading { return d.index().headings }
+
+// Links returns all links, including autolinks, in source order.
+func (d *Document) Links() []Link { return d.index().links }
+
+// Images returns all images in source order.
+func (d *Document) Images() []Image { return d.index().images }
+
+// CodeSpans returns all inline code spans in source order.
+func (d *Document) CodeSpans() []CodeSpan { return d.index().codeSpans }
+
+// FencedBlocks returns all fenced code blocks in source order.
+func (d *Document) FencedBlocks() []FencedBlock { return d.index().fencedCode }
+
+// Tables returns all tables in source order.
+func (d *Document) Tables() []Table { return d.index().tables }
+
+// ListItems returns all list items in source order.
+func (d *Document) ListItems() []ListItem { return d.index().listItems }
+
+// Paragraphs returns all paragraphs in source order.
+func (d *Document) Paragraphs() []Paragraph { return d.index().paragraphs }
+
+// Comments returns all HTML comments in source order.
+func (d *Document) Comments() []Comment { return d.index().comments }
+
+// NodeSpan returns the source span covered by any node of the document.
+func (d *Document) NodeSpan(n ast.Node) Span {
+	return d.Span(d.extent(n))
+}
+
+// index walks the AST once, on first use, and builds the node facades.
+func (d *Document) index() *nodeIndex {
+	d.nodesOnce.Do(func() {
+		idx := &nodeIndex{}
+		src := d.Source
+		_ = ast.Walk(d.Root, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+			if !entering {
+				return ast.WalkContinue, nil
+			}
+			switch n := n.(type) {
+			case *ast.Heading:
+				start, end, setext := d.headingExtent(n)
+				idx.headings = append(idx.headings, Heading{
+					Span:   d.Span(start, end),
+					Level:  n.Level,
+					Text:   plainText(n, src),
+					Setext: setext,
+					Node:   n,
+				})
+			case *ast.Link:
+				span := d.NodeSpan(n)
+				idx.links = append(idx.links, Link{
+					Span:        span,
+					Text:        plainText(n, src),
+					Destination: string(n.Destination),
+					Title:       string(n.Title),
+					Reference:   linkReference(src[span.Start.Offset:span.End.Offset]),
+					Node:        n,
+				})
+			case *ast.AutoLink:
+				idx.links = append(idx.links, Link{
+					Span:        d.NodeSpan(n),
+					Text:        string(n.Label(src)),
+					Destination: string(n.URL(src)),
+					Autolink:    true,
+					Node:        n,
+				})
+			case *ast.Image:
+				idx.images = append(idx.images, Image{
+					Span:        d.NodeSpan(n),
+					Alt:         plainText(n, src),
+					Destination: string(n.Destination),
+					Title:       string(n.Title),
+					Node:        n,
+				})
+			case *ast.CodeSpan:
+				idx.codeSpans = append(idx.codeSpans, CodeSpan{
+					Span: d.NodeSpan(n),
+					Text: plainText(n, src),
+					Node: n,
+				})
+				return ast.WalkSkipChildren, nil
+			case *ast.FencedCodeBlock:
+				idx.fencedCode = append(idx.fencedCode, d.fencedBlock(n))
+				return ast.WalkSkipChildren, nil
+			case *east.Table:
+				idx.tables = append(idx.tables, d.table(n))
+			case *ast.ListItem:
+				item := ListItem{Span: d.NodeSpan(n), Node: n}
+				if list, ok := n.Parent().(*ast.List); ok {
+					item.Ordered = list.IsOrdered()
+				}
+				item.Marker = d.listMarker(item.Span)
+				idx.listItems = append(idx.listItems, item)
+			case *ast.Paragraph, *ast.TextBlock:
+				idx.paragraphs = append(idx.paragraphs, Paragraph{Span: d.NodeSpan(n), Node: n})
+			case *ast.HTMLBlock:
+				if lines := n.Lines(); lines.Len() > 0 {
+					end := lines.At(lines.Len() - 1).Stop
+					if n.HasClosure() {
+						end = n.ClosureLine.Stop
+					}
+					idx.comments = append(idx.comments, d.comments(lines.At(0).Start, end)...)
+				}
+			case *ast.RawHTML:
+				idx.comments = append(idx.comments, d.comments(d.extent(n))...)
+			}
+			return ast.WalkContinue, nil
+		})
+		d.nodes = idx
+	})
+	return d.nodes
+}
+
+// comments returns the HTML comments found in the source range [start, end).
+func (d *Document) comments(start, end int) []Comment {
+	var result []Comment
+	for start < end {
+		begin := bytes.Index(d.Source[start:end], []byte("<!--"))
+		if begin < 0 {
+			break
+		}
+		begin += start
+		finish := bytes.Index(d.Source[begin+4:end], []byte("-->"))
+		if finish < 0 {
+			break
+		}
+		finish += begin + 4
+		result = append(result, Comment{
+			Span: d.Span(begin, finish+3),
+			Text: strings.TrimSpace(string(d.Source[begin+4 : finish])),
+		})
+		start = finish + 3
+	}
+	return result
+}
+
+// extent returns the byte range [start, end) covered by n. Results are cached
+// since the extent of a node without source segments derives from those of
+// its preceding siblings.
+func (d *Document) extent(n ast.Node) (int, int) {
+	if r, ok := d.extents.Load(n); ok {
+		r := r.([2]int)
+		return r[0], r[1]
+	}
+	start, end := d.measure(n)
+	d.extents.Store(n, [2]int{start, end})
+	return start, end
+}
+
+// measure computes the extent of n.
+func (d *Document) measure(n ast.Node) (int, int) {
+	switch n := n.(type) {
+	case *ast.Document:
+		return 0, len(d.Source)
+	case *ast.Text:
+		return n.Segment.Start, n.Segment.Stop
+	case *ast.RawHTML:
+		if n.Segments.Len() > 0 {
+			return n.Segments.At(0).Start, n.Segments.At(n.Segments.Len() - 1).Stop
+		}
+	case *ast.CodeSpan:
+		return d.codeSpanExtent(n)
+	case *ast.Link:
+		return d.linkExtent(n, false)
+	case *ast.Image:
+		return d.linkExtent(n, true)
+	case *ast.AutoLink:
+		return d.autoLinkExtent(n)
+	case *ast.Heading:
+		start, end, _ := d.headingExtent(n)
+		return start, end
+	case *ast.FencedCodeBlock:
+		fb := d.fencedBlock(n)
+		return fb.Start.Offset, fb.End.Offset
+	case *ast.ListItem:
+		return d.listItemExtent(n)
+	case *ast.Blockquote:
+		return d.blockquoteExtent(n)
+	case *east.TableRow:
+		return d.rowExtent(n)
+	case *east.TableHeader:
+		return d.rowExtent(n)
+	}
+	if n.Type() == ast.TypeBlock && n.Lines().Len() > 0 {
+		lines := n.Lines()
+		return d.trim(lines.At(0).Start, lines.At(lines.Len()-1).Stop)
+	}
+	if n.FirstChild() != nil {
+		start, _ := d.extent(n.FirstChild())
+		_, end := d.extent(n.LastChild())
+		if e, ok := n.(*ast.Emphasis); ok {
+			start, end = d.widen(start, end, e.Level)
+		}
+		return start, end
+	}
+	a := d.anchor(n)
+	if n.Type() == ast.TypeBlock {
+		a = d.skipBlankLines(a)
+	}
+	return a, a
+}
+
+// anchor returns an offset at or before the start of n for nodes that carry
+// no source segments of their own. It is derived from the preceding sibling
+// or, for a first child, from the enclosing node.
+func (d *Document) anchor(n ast.Node) int {
+	if prev := n.PreviousSibling(); prev != nil {
+		_, end := d.extent(prev)
+		return end
+	}
+	p := n.Parent()
+	if p == nil || p.Kind() == ast.KindDocument {
+		if d.HasFrontMatter {
+			return d.LineOffset(d.FrontMatter.EndLine + 1)
+		}
+		return 0
+	}
+	if p.Type() == ast.TypeBlock && p.Lines().Len() > 0 {
+		return p.Lines().At(0).Start
+	}
+	return d.anchor(p)
+}
+
+// widen grows [start, end) by up to level emphasis delimiters on each side.
+func (d *Document) widen(start, end, level int) (int, int) {
+	src := d.Source
+	for i := 0; i < level && start > 0 && (src[start-1] == '*' || src[start-1] == '_' || src[start-1] == '~'); i++ {
+		start--
+	}
+	for i := 0; i < level && end < len(src) && (src[end] == '*' || src[end] == '_' || src[end] == '~'); i++ {
+		end++
+	}
+	return start, end
+}
+
+func (d *Document) headingExtent(h *ast.Heading) (start, end int, setext bool) {
+	src := d.Source
+	if h.Lines().Len() == 0 {
+		// An empty ATX heading such as "##" has no content segment.
+		a := d.skipBlankLines(d.anchor(h))
+		le := d.lineEnd(a)
+		if i := bytes.IndexByte(src[a:le], '#'); i >= 0 {
+			a += i
+		}
+		start, end = d.trim(a, le)
+		return start, end, false
+	}
+	first := h.Lines().At(0)
+	last := h.Lines().At(h.Lines().Len() - 1)
+	i := first.Start
+	for i > 0 && (src[i-1] == ' ' || src[i-1] == '\t') {
+		i--
+	}
+	if h.Lines().Len() == 1 && i > 0 && src[i-1] == '#' {
+		for i > 0 && src[i-1] == '#' {
+			i--
+		}
+		_, end = d.trim(i, d.lineEnd(first.Start))
+		return i, end, false
+	}
+	start, _ = d.trim(first.Start, last.Stop)
+	underline := d.lineEnd(last.Start) + 1
+	if underline > len(src) {
+		underline = len(src)
+	}
+	_, end = d.trim(underline, d.lineEnd(underline))
+	return start, end, true
+}
+
+func (d *Document) codeSpanExtent(n *ast.CodeSpan) (int, int) {
+	src := d.Source
+	first, last := firstText(n), lastText(n)
+	if first == nil {
+		a := d.anchor(n)
+		return a, a
+	}
+	start, end := first.Segment.Start, last.Segment.Stop
+	i := start
+	for i > 0 && src[i-1] == ' ' {
+		i--
+	}
+	if i > 0 && src[i-1] == '`' {
+		for i > 0 && src[i-1] == '`' {
+			i--
+		}
+		start = i
+	}
+	j := end
+	for j < len(src) && src[j] == ' ' {
+		j++
+	}
+	if j < len(src) && src[j] == '`' {
+		for j < len(src) && src[j] == '`' {
+			j++
+		}
+		end = j
+	}
+	return start, end
+}
+
+func (d *Document) linkExtent(n ast.Node, image bool) (int, int) {
+	src := d.Source
+	var start, textEnd int
+	if first := firstText(n); first != nil {
+		lower := d.anchor(n)
+		start = first.Segment.Start
+		for start > lower && src[start-1] != '[' {
+			start--
+		}
+		if start > 0 {
+			start--
+		}
+		textEnd = lastText(n).Segment.Stop
+	} else {
+		a := d.anchor(n)
+		start = a
+		if i := bytes.IndexByte(src[a:], '['); i >= 0 {
+			start = a + i
+		}
+		textEnd = start + 1
+	}
+	if image && start > 0 && src[start-1] == '!' {
+		start--
+	}
+	end := textEnd
+	if i := bytes.IndexByte(src[textEnd:], ']'); i >= 0 {
+		end = textEnd + i + 1
+	}
+	return start, linkTail(src, end)
+}
+
+// linkTail returns the end of the destination or reference label starting
+// at i, just after a link's closing bracket.
+func linkTail(src []byte, i int) int {
+	if i >= len(src) {
+		return i
+	}
+	switch src[i] {
+	case '(':
+		depth := 0
+		var quote byte
+		for j := i; j < len(src); j++ {
+			c := src[j]
+			switch {
+			case c == '\\':
+				j++
+			case quote != 0:
+				if c == quote {
+					quote = 0
+				}
+			case (c == '"' || c == '\'') && j > 0 && isSpace(src[j-1]):
+				quote = c
+			case c == '(':
+				depth++
+			case c == ')':
+				depth--
+				if depth == 0 {
+					return j + 1
+				}
+			}
+		}
+	case '[':
+		if k := bytes.IndexByte(src[i:], ']'); k >= 0 {
+			return i + k + 1
+		}
+	}
+	return i
+}
+
+// linkReference returns the normalized reference label of the link source s,
+// or "" when s is an inline link. Full, collapsed and shortcut references are
+// recognized.
+func linkReference(s []byte) string {
+	if len(s) < 2 || s[len(s)-1] != ']' {
+		return ""
+	}
+	k := bytes.LastIndexByte(s[:len(s)-1], '[')
+	if k < 0 {
+		return ""
+	}
+	label := s[k+1 : len(s)-1]
+	if k == 0 {
+		// Shortcut reference: the link text is the label.
+		return util.ToLinkReference(label)
+	}
+	if len(label) == 0 {
+		// Collapsed reference: the link text precedes the empty brackets.
+		label = bytes.TrimSuffix(bytes.TrimPrefix(s[:k], []byte("[")), []byte("]"))
+	}
+	return util.ToLinkReference(label)
+}
+
+func (d *Document) autoLinkExtent(n *ast.AutoLink) (int, int) {
+	src := d.Source
+	a := d.anchor(n)
+	label := n.Label(src)
+	i := bytes.Index(src[a:], label)
+	if i < 0 {
+		return a, a
+	}
+	start, end := a+i, a+i+len(label)
+	if start > 0 && src[start-1] == '<' && end < len(src) && src[end] == '>' {
+		start--
+		end++
+	}
+	return start, end
+}
+
+func (d *Document) blockquoteExtent(n *ast.Blockquote) (int, int) {
+	src := d.Source
+	if n.FirstChild() == nil {
+		a := d.skipBlankLines(d.anchor(n))
+		_, end := d.trim(a, d.lineEnd(a))
+		return a, end
+	}
+	start, _ := d.extent(n.FirstChild())
+	_, end := d.extent(n.LastChild())
+	begin := d.lineBegin(start)
+	for i := start; i > begin; i-- {
+		if src[i-1] == '>' {
+			start = i - 1
+			break
+		}
+	}
+	return start, end
+}
+
+func (d *Document) listItemExtent(n *ast.ListItem) (int, int) {
+	src := d.Source
+	if n.FirstChild() == nil {
+		a := d.skipBlankLines(d.anchor(n))
+		_, end := d.trim(a, d.lineEnd(a))
+		return a, end
+	}
+	start, _ := d.extent(n.FirstChild())
+	_, end := d.extent(n.LastChild())
+	i := start
+	for i > 0 && (src[i-1] == ' ' || src[i-1] == '\t') {
+		i--
+	}
+	if m := markerBefore(src, i); m >= 0 {
+		return m, end
+	}
+	// The marker may stand alone on the line before the content.
+	if begin := d.lineBegin(start); begin > 0 {
+		prevStart, prevEnd := d.trim(d.lineBegin(begin-1), begin)
+		if m := markerBefore(src, prevEnd); m == prevStart {
+			return m, end
+		}
+	}
+	return start, end
+}
+
+// markerBefore returns the offset of the list marker ending just before i, or
+// -1 if there is none.
+func markerBefore(src []byte, i int) int {
+	switch {
+	case i > 0 && (src[i-1] == '-' || src[i-1] == '*' || src[i-1] == '+'):
+		return i - 1
+	case i > 0 && (src[i-1] == '.' || src[i-1] == ')'):
+		j := i - 1
+		for j > 0 && src[j-1] >= '0' && src[j-1] <= '9' {
+			j--
+		}
+		if j < i-1 {
+			return j
+		}
+	}
+	return -1
+}
+
+// listMarker returns the marker at the start of a list item span.
+func (d *Document) listMarker(s Span) string {
+	src := d.Source
+	i := s.Start.Offset
+	j := i
+	for j < s.End.Offset && src[j] >= '0' && src[j] <= '9' {
+		j++
+	}
+	if j < s.End.Offset && strings.IndexByte("-*+.)", src[j]) >= 0 {
+		j++
+	}
+	return string(src[i:j])
+}
+
+func (d *Document) rowExtent(row ast.Node) (int, int) {
+	for c := row.FirstChild(); c != nil; c = c.NextSibling() {
+		if c.Lines().Len() > 0 {
+			off := c.Lines().At(0).Start
+			return d.trim(d.lineBegin(off), d.lineEnd(off))
+		}
+	}
+	a := d.skipBlankLines(d.anchor(row))
+	return d.trim(a, d.lineEnd(a))
+}
+
+func (d *Document) table(n *east.Table) Table {
+	t := Table{Node: n}
+	for r := n.FirstChild(); r != nil; r = r.NextSibling() {
+		row := TableRow{Span: d.NodeSpan(r)}
+		_, row.Header = r.(*east.TableHeader)
+		for c := r.FirstChild(); c != nil; c = c.NextSibling() {
+			row.Cells = append(row.Cells, d.NodeSpan(c))
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	if len(t.Rows) > 0 {
+		t.Span = Span{Start: t.Rows[0].Start, End: t.Rows[len(t.Rows)-1].End}
+	}
+	return t
+}
+
+func (d *Document) fencedBlock(n *ast.FencedCodeBlock) FencedBlock {
+	src := d.Source
+	fb := FencedBlock{Node: n, Language: string(n.Language(src))}
+	lines := n.Lines()
+
+	// Locate a byte on the opening fence line.
+	var open int
+	switch {
+	case n.Info != nil:
+		open = n.Info.Segment.Start
+		fb.Info = string(n.Info.Segment.Value(src))
+	case lines.Len() > 0:
+		open = d.lineBegin(d.lineBegin(lines.At(0).Start) - 1)
+	default:
+		open = d.skipBlankLines(d.anchor(n))
+	}
+	lineStart, lineEnd := d.lineBegin(open), d.lineEnd(open)
+	fs := lineStart
+	for fs < lineEnd && src[fs] != '`' && src[fs] != '~' {
+		fs++
+	}
+	fe := fs
+	for fe < lineEnd && fe < len(src) && src[fe] == src[fs] {
+		fe++
+	}
+	fb.Fence = d.Span(fs, fe)
+	_, end := d.trim(fs, lineEnd)
+
+	next := lineEnd + 1
+	if lines.Len() > 0 {
+		first, last := lines.At(0), lines.At(lines.Len()-1)
+		fb.Content = d.Span(first.Start, last.Stop)
+		_, end = d.trim(fs, last.Stop)
+		next = d.lineEnd(last.Start) + 1
+	} else {
+		fb.Content = d.Span(next, next)
+	}
+	if fe > fs && next < len(src) {
+		closeEnd := d.lineEnd(next)
+		line := bytes.TrimLeft(src[next:closeEnd], " \t>")
+		if bytes.HasPrefix(line, src[fs:fe]) {
+			fb.Closed = true
+			_, end = d.trim(next, closeEnd)
+		}
+	}
+	fb.Span = d.Span(fs, end)
+	return fb
+}
+
+// firstText returns the first Text node below n.
+func firstText(n ast.Node) *ast.Text {
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		if t, ok := c.(*ast.Text); ok {
+			return t
+		}
+		if t := firstText(c); t != nil {
+			return t
+		}
+	}
+	return nil
+}
+
+// lastText returns the last Text node below n.
+func lastText(n ast.Node) *ast.Text {
+	for c := n.LastChild(); c != nil; c = c.PreviousSibling() {
+		if t, ok := c.(*ast.Text); ok {
+			return t
+		}
+		if t := lastText(c); t != nil {
+			return t
+		}
+	}
+	return nil
+}
+
+// plainText concatenates the text below n, joining soft line breaks with a
+// space.
+func plainText(n ast.Node, src []byte) string {
+	var sb strings.Builder
+	_ = ast.Walk(n, func(c ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch c := c.(type) {
+		case *ast.Text:
+			sb.Write(c.Segment.Value(src))
+			if c.SoftLineBreak() || c.HardLineBreak() {
+				sb.WriteByte(' ')
+			}
+		case *ast.String:
+			sb.Write(c.Value)
+		case *ast.AutoLink:
+			sb.Write(c.Label(src))
+		}
+		return ast.WalkContinue, nil
+	})
+	return strings.TrimSpace(sb.String())
+}
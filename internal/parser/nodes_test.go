@@ -0,0 +1,190 @@
+// Copyright 2025 Sam Caldwell
+// SPDX-License-Identifier: MIT
+
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+const sample = `---
+title: x
+---
+
+# Title
+
+Setext
+------
+
+Some [link](https://example.com "t") and ![alt](img.png) with ` + "`code`" + `.
+See <https://auto.example> too.
+
+- first item
+- [x] done
+
+10. tenth
+
+` + "```go" + `
+fmt.Println()
+` + "```" + `
+
+| a | b |
+| - | - |
+| 1 | 2 |
+
+##
+`
+
+// source returns the source covered by s.
+func source(doc *Document, s Span) string {
+	return string(doc.Source[s.Start.Offset:s.End.Offset])
+}
+
+func TestHeadings(t *testing.T) {
+	doc := Parse("", []byte(sample))
+	hs := doc.Headings()
+	if len(hs) != 3 {
+		t.Fatalf("expected 3 headings, got %d", len(hs))
+	}
+	tests := []struct {
+		text, src   string
+		line, level int
+		setext      bool
+	}{
+		{"Title", "# Title", 5, 1, false},
+		{"Setext", "Setext\n------", 7, 2, true},
+		{"", "##", 26, 2, false},
+	}
+	for i, tt := range tests {
+		h := hs[i]
+		if h.Text != tt.text || source(doc, h.Span) != tt.src || h.Start.Line != tt.line ||
+			h.Start.Column != 1 || h.Level != tt.level || h.Setext != tt.setext {
+			t.Fatalf("heading %d: got %+v (%q)", i, h, source(doc, h.Span))
+		}
+	}
+	if end := hs[1].End; end.Line != 8 || end.Column != 7 {
+		t.Fatalf("unexpected setext end: %+v", end)
+	}
+}
+
+func TestInlineNodes(t *testing.T) {
+	doc := Parse("", []byte(sample))
+
+	links := doc.Links()
+	if len(links) != 2 {
+		t.Fatalf("expected 2 links, got %d", len(links))
+	}
+	if l := links[0]; source(doc, l.Span) != `[link](https://example.com "t")` ||
+		l.Start.Line != 10 || l.Start.Column != 6 || l.Destination != "https://example.com" || l.Title != "t" {
+		t.Fatalf("unexpected link: %+v", l)
+	}
+	if l := links[1]; source(doc, l.Span) != "<https://auto.example>" || !l.Autolink || l.Start.Line != 11 {
+		t.Fatalf("unexpected autolink: %+v", l)
+	}
+
+	imgs := doc.Images()
+	if len(imgs) != 1 || source(doc, imgs[0].Span) != "![alt](img.png)" || imgs[0].Alt != "alt" {
+		t.Fatalf("unexpected images: %+v", imgs)
+	}
+
+	spans := doc.CodeSpans()
+	if len(spans) != 1 || source(doc, spans[0].Span) != "`code`" || spans[0].Text != "code" {
+		t.Fatalf("unexpected code spans: %+v", spans)
+	}
+}
+
+// TestEmptyLinks verifies that links without text, whose positions derive
+// from their preceding siblings, are located on a long line; measuring every
+// sibling anew took seconds.
+func TestEmptyLinks(t *testing.T) {
+	const n = 12000
+	doc := Parse("", []byte(strings.Repeat("[]()", n)+"\n"))
+	links := doc.Links()
+	if len(links) != n {
+		t.Fatalf("expected %d links, got %d", n, len(links))
+	}
+	if l := links[n-1]; source(doc, l.Span) != "[]()" || l.Start.Column != 4*(n-1)+1 {
+		t.Fatalf("unexpected last link: %+v", l)
+	}
+}
+
+func TestBlockNodes(t *testing.T) {
+	doc := Parse("", []byte(sample))
+
+	items := doc.ListItems()
+	if len(items) != 3 {
+		t.Fatalf("expected 3 list items, got %d", len(items))
+	}
+	if source(doc, items[0].Span) != "- first item" || items[0].Marker != "-" || items[0].Ordered {
+		t.Fatalf("unexpected item: %+v", items[0])
+	}
+	if source(doc, items[1].Span) != "- [x] done" {
+		t.Fatalf("unexpected task item: %q", source(doc, items[1].Span))
+	}
+	if items[2].Marker != "10." || !items[2].Ordered || items[2].Start.Line != 16 {
+		t.Fatalf("unexpected ordered item: %+v", items[2])
+	}
+
+	fbs := doc.FencedBlocks()
+	if len(fbs) != 1 {
+		t.Fatalf("expected 1 fenced block, got %d", len(fbs))
+	}
+	fb := fbs[0]
+	if fb.Start.Line != 18 || fb.End.Line != 20 || !fb.Closed || fb.Language != "go" ||
+		source(doc, fb.Fence) != "```" || source(doc, fb.Content) != "fmt.Println()\n" {
+		t.Fatalf("unexpected fenced block: %+v", fb)
+	}
+
+	tables := doc.Tables()
+	if len(tables) != 1 {
+		t.Fatalf("expected 1 table, got %d", len(tables))
+	}
+	tb := tables[0]
+	if tb.Start.Line != 22 || tb.End.Line != 24 || len(tb.Rows) != 2 || !tb.Rows[0].Header {
+		t.Fatalf("unexpected table: %+v", tb)
+	}
+	if c := tb.Rows[1].Cells[1]; source(doc, c) != "2" || c.Start.Column != 7 {
+		t.Fatalf("unexpected cell: %+v", c)
+	}
+
+	paras := doc.Paragraphs()
+	if len(paras) == 0 || paras[0].Start.Line != 10 || paras[0].End.Line != 11 {
+		t.Fatalf("unexpected paragraphs: %+v", paras)
+	}
+}
+
+func TestFencedBlockEdgeCases(t *testing.T) {
+	doc := Parse("", []byte("text\n\n~~~~\n~~~~\n\n```\nunclosed"))
+	fbs := doc.FencedBlocks()
+	if len(fbs) != 2 {
+		t.Fatalf("expected 2 fenced blocks, got %d", len(fbs))
+	}
+	if fb := fbs[0]; fb.Start.Line != 3 || fb.End.Line != 4 || !fb.Closed || fb.Content.Len() != 0 {
+		t.Fatalf("unexpected empty block: %+v", fb)
+	}
+	if fb := fbs[1]; fb.Start.Line != 6 || fb.End.Line != 7 || fb.Closed {
+		t.Fatalf("unexpected unclosed block: %+v", fb)
+	}
+}
+
+func TestComments(t *testing.T) {
+	src := "<!-- block -->\n\nText <!-- inline --> here.\n\n<!--\nmulti\nline\n-->\n\n`<!-- code -->`\n\n```\n<!-- fenced -->\n```\n"
+	doc := Parse("", []byte(src))
+	cs := doc.Comments()
+	if len(cs) != 3 {
+		t.Fatalf("expected 3 comments, got %+v", cs)
+	}
+	want := []struct {
+		text string
+		line int
+	}{{"block", 1}, {"inline", 3}, {"multi\nline", 5}}
+	for i, w := range want {
+		if cs[i].Text != w.text || cs[i].Start.Line != w.line || source(doc, cs[i].Span)[:4] != "<!--" {
+			t.Fatalf("comment %d: got %+v", i, cs[i])
+		}
+	}
+	if cs[2].End.Line != 8 {
+		t.Fatalf("unexpected multi-line comment end: %+v", cs[2].End)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2024 Asymmetric Effort
+
+package parser
+
+// Pos is a location within a document.
+type Pos struct {
+	// Offset is the 0-based byte offset from the start of the source.
+	Offset int
+	// Line is the 1-based line number.
+	Line int
+	// Column is the 1-based byte column within the line.
+	Column int
+}
+
+// Span is the half-open source range [Start, End) covered by a node. End is
+// the position just past the node's last byte.
+type Span struct {
+	Start Pos
+	End   Pos
+}
+
+// Len returns the number of bytes covered by the span.
+func (s Span) Len() int { return s.End.Offset - s.Start.Offset }
+
+// Pos returns the position of the given byte offset. Offsets outside the
+// source are clamped to its bounds.
+func (d *Document) Pos(offset int) Pos {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > len(d.Source) {
+		offset = len(d.Source)
+	}
+	line, col := d.Position(offset)
+	return Pos{Offset: offset, Line: line, Column: col}
+}
+
+// Span returns the span covering the byte range [start, end).
+func (d *Document) Span(start, end int) Span {
+	if end < start {
+		end = start
+	}
+	return Span{Start: d.Pos(start), End: d.Pos(end)}
+}
+
+// lineEnd returns the offset of the newline terminating the line containing
+// offset, or the length of the source for the last line.
+func (d *Document) lineEnd(offset int) int {
+	for i := offset; i < len(d.Source); i++ {
+		if d.Source[i] == '\n' {
+			return i
+		}
+	}
+	return len(d.Source)
+}
+
+// lineBegin returns the offset of the first byte of the line containing
+// offset.
+func (d *Document) lineBegin(offset int) int {
+	line, _ := d.Position(offset)
+	return d.LineOffset(line)
+}
+
+// trim shrinks [start, end) to exclude surrounding spaces, tabs and line
+// endings.
+func (d *Document) trim(start, end int) (int, int) {
+	for start < end && isSpace(d.Source[start]) {
+		start++
+	}
+	for end > start && isSpace(d.Source[end-1]) {
+		end--
+	}
+	return start, end
+}
+
+// skipBlankLines advances offset past whitespace and empty lines and returns
+// the offset of the next non-space byte.
+func (d *Document) skipBlankLines(offset int) int {
+	for offset < len(d.Source) && isSpace(d.Source[offset]) {
+		offset++
+	}
+	return offset
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
+}
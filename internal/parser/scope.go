@@ -0,0 +1,117 @@
+// Copyright (c) 2024 Asymmetric Effort
+
+package parser
+
+import (
+	"sort"
+
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+
+	"github.com/asymmetric-effort/mdlint/internal/markdown"
+)
+
+// Scope is a set of block contexts in which prose appears. Rules combine
+// scopes with '|' to select the prose they inspect.
+type Scope uint
+
+// Prose scopes. A segment carries the scope of the block holding it along with
+// the scopes of every enclosing list item or blockquote.
+const (
+	// ScopeParagraph is paragraph text, including tight list item text.
+	ScopeParagraph Scope = 1 << iota
+	// ScopeHeading is ATX and setext heading text.
+	ScopeHeading
+	// ScopeTableCell is the text of table header and body cells.
+	ScopeTableCell
+	// ScopeListItem is prose nested in a list item.
+	ScopeListItem
+	// ScopeBlockquote is prose nested in a blockquote.
+	ScopeBlockquote
+
+	// ScopeAll selects prose in every scope.
+	ScopeAll = ScopeParagraph | ScopeHeading | ScopeTableCell | ScopeListItem | ScopeBlockquote
+)
+
+// Segment is a contiguous run of prose text. Code spans and blocks, raw and
+// block HTML, autolinks, bare URLs and front matter never appear in segments,
+// so Text is always exactly the source covered by Span.
+type Segment struct {
+	Span
+	// Text is the source text of the segment.
+	Text string
+	// Scope holds the scopes the segment appears in.
+	Scope Scope
+}
+
+// Prose returns the prose segments in source order whose scopes are all
+// included in scopes. For example ScopeParagraph selects top-level paragraphs
+// only, while ScopeParagraph|ScopeListItem adds paragraphs within lists.
+func (d *Document) Prose(scopes Scope) []Segment {
+	var segs []Segment
+	for _, s := range d.indexProse().segments {
+		if s.Scope&^scopes == 0 {
+			segs = append(segs, s)
+		}
+	}
+	return segs
+}
+
+// proseBlock is a leaf block holding prose, with its text runs.
+type proseBlock struct {
+	scope Scope
+	runs  []run
+}
+
+// proseBlocks returns the blocks holding prose in source order.
+func (d *Document) proseBlocks() []proseBlock {
+	var blocks []proseBlock
+	var visit func(n ast.Node, scope Scope)
+	visit = func(n ast.Node, scope Scope) {
+		switch n.(type) {
+		case *ast.Paragraph, *ast.TextBlock:
+			blocks = append(blocks, proseBlock{scope: scope | ScopeParagraph, runs: d.runs(n)})
+			return
+		case *ast.Heading:
+			blocks = append(blocks, proseBlock{scope: scope | ScopeHeading, runs: d.runs(n)})
+			return
+		case *east.TableCell:
+			blocks = append(blocks, proseBlock{scope: scope | ScopeTableCell, runs: d.runs(n)})
+			return
+		case *ast.ListItem:
+			scope |= ScopeListItem
+		case *ast.Blockquote:
+			scope |= ScopeBlockquote
+		}
+		for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+			visit(c, scope)
+		}
+	}
+	visit(d.Root, 0)
+	return blocks
+}
+
+// codeBlocks returns the line ranges of every fenced and indented code block,
+// including fences nested in lists or blockquotes, empty blocks and blocks
+// left unclosed at the end of the document.
+func (d *Document) codeBlocks() []markdown.Range {
+	var ranges []markdown.Range
+	for _, b := range d.FencedBlocks() {
+		end := b.End
+		if end.Column == 1 && end.Line > b.Start.Line {
+			end = d.Pos(end.Offset - 1)
+		}
+		ranges = append(ranges, markdown.Range{StartLine: b.Start.Line, EndLine: end.Line})
+	}
+	_ = ast.Walk(d.Root, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if cb, ok := n.(*ast.CodeBlock); ok && entering && cb.Lines().Len() > 0 {
+			lines := cb.Lines()
+			first, _ := d.Position(lines.At(0).Start)
+			last, _ := d.Position(lines.At(lines.Len()-1).Stop - 1)
+			ranges = append(ranges, markdown.Range{StartLine: first, EndLine: last})
+		}
+		return ast.WalkContinue, nil
+	})
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].StartLine < ranges[j].StartLine })
+	return ranges
+}
@@ -0,0 +1,110 @@
+// Copyright 2025 Sam Caldwell
+// SPDX-License-Identifier: MIT
+
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/asymmetric-effort/mdlint/internal/markdown"
+)
+
+const scoped = `---
+title: Front
+---
+
+# Heading
+
+Para with ` + "`code`" + ` and <b>html</b> and https://bare.example.
+
+- item text
+  ` + "```" + `
+  nested fence
+  ` + "```" + `
+
+> quoted text
+> ` + "```" + `
+> quoted fence
+> ` + "```" + `
+
+    indented code
+
+<div>
+block html
+</div>
+
+| cell |
+| ---- |
+`
+
+func TestProse(t *testing.T) {
+	doc := Parse("", []byte(scoped))
+
+	var all []string
+	for _, s := range doc.Prose(ScopeAll) {
+		if got := source(doc, s.Span); got != s.Text {
+			t.Fatalf("segment %q covers %q", s.Text, got)
+		}
+		all = append(all, s.Text)
+	}
+	want := []string{"Heading", "Para with ", " and ", "html", " and ", ".", "item text", "quoted text", "cell"}
+	if !reflect.DeepEqual(all, want) {
+		t.Fatalf("unexpected segments: %q", all)
+	}
+
+	tests := []struct {
+		scopes Scope
+		want   []string
+	}{
+		{ScopeHeading, []string{"Heading"}},
+		{ScopeParagraph, []string{"Para with ", " and ", "html", " and ", "."}},
+		{ScopeParagraph | ScopeListItem, []string{"Para with ", " and ", "html", " and ", ".", "item text"}},
+		{ScopeBlockquote, nil},
+		{ScopeParagraph | ScopeBlockquote, []string{"Para with ", " and ", "html", " and ", ".", "quoted text"}},
+		{ScopeTableCell, []string{"cell"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, s := range doc.Prose(tt.scopes) {
+			got = append(got, s.Text)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Fatalf("Prose(%b) = %q, want %q", tt.scopes, got, tt.want)
+		}
+	}
+}
+
+func TestWordScopes(t *testing.T) {
+	doc := Parse("", []byte(scoped))
+	want := map[string]Scope{
+		"Heading": ScopeHeading,
+		"Para":    ScopeParagraph,
+		"item":    ScopeParagraph | ScopeListItem,
+		"quoted":  ScopeParagraph | ScopeBlockquote,
+		"cell":    ScopeTableCell,
+	}
+	for _, w := range doc.Words() {
+		if scope, ok := want[w.Text]; ok && w.Scope != scope {
+			t.Fatalf("word %q has scope %b, want %b", w.Text, w.Scope, scope)
+		}
+		delete(want, w.Text)
+	}
+	if len(want) != 0 {
+		t.Fatalf("words not found: %v", want)
+	}
+}
+
+func TestCodeBlocks(t *testing.T) {
+	doc := Parse("", []byte(scoped))
+	want := []markdown.Range{{StartLine: 10, EndLine: 12}, {StartLine: 15, EndLine: 17}, {StartLine: 19, EndLine: 19}}
+	if !reflect.DeepEqual(doc.CodeBlocks, want) {
+		t.Fatalf("unexpected code blocks: %+v", doc.CodeBlocks)
+	}
+
+	doc = Parse("", []byte("```\n```\n\ntext\n\n~~~\nopen\n"))
+	want = []markdown.Range{{StartLine: 1, EndLine: 2}, {StartLine: 6, EndLine: 7}}
+	if !reflect.DeepEqual(doc.CodeBlocks, want) || doc.InCodeBlock(4) {
+		t.Fatalf("unexpected code blocks: %+v", doc.CodeBlocks)
+	}
+}
@@ -0,0 +1,12 @@
+// Copyright (c) 2024 MdLint contributors.
+// SPDX-License-Identifier: MIT
+
+package rules
+
+// Rules implemented in their own packages register themselves with the engine
+// when imported; importing this package makes every built-in rule available.
+import (
+	_ "github.com/asymmetric-effort/mdlint/internal/rules/md1000"
+	_ "github.com/asymmetric-effort/mdlint/internal/rules/md1100"
+	_ "github.com/asymmetric-effort/mdlint/internal/rules/md1400"
+)
@@ -4,56 +4,67 @@ import (
 	"testing"
 
 	"github.com/asymmetric-effort/mdlint/internal/engine"
+	"github.com/asymmetric-effort/mdlint/internal/findings"
+	"github.com/asymmetric-effort/mdlint/internal/parser"
 	"github.com/asymmetric-effort/mdlint/internal/rules/md1000"
 )
 
+func apply(t *testing.T, cfg md1000.Config, content string) []findings.Finding {
+	t.Helper()
+	f, err := md1000.Rule{}.Apply(parser.Parse("", []byte(content)), engine.RuleConfig{Options: cfg})
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	return f
+}
+
 func TestMD1000Rule_Basic(t *testing.T) {
-	rule, ok := engine.GetRule("MD1000")
-	if !ok {
+	if _, ok := engine.GetRule("MD1000"); !ok {
 		t.Fatalf("MD1000 rule not registered")
 	}
-	cfg := md1000.Config{LineLength: 10}
 	content := "short\nthis line is way too long\n"
-	findings := rule.Apply(content, cfg)
-	if len(findings) != 1 {
-		t.Fatalf("expected 1 finding, got %d", len(findings))
+	f := apply(t, md1000.Config{LineLength: 10}, content)
+	if len(f) != 1 {
+		t.Fatalf("expected 1 finding, got %d", len(f))
 	}
-	if findings[0].Line != 2 {
-		t.Fatalf("expected finding on line 2, got %d", findings[0].Line)
+	if f[0].Line != 2 {
+		t.Fatalf("expected finding on line 2, got %d", f[0].Line)
 	}
 }
 
 func TestMD1000Rule_CodeBlockOption(t *testing.T) {
-	rule, _ := engine.GetRule("MD1000")
 	content := "```\nlong line inside code block that should not trigger\n```\n"
 	cfg := md1000.Config{LineLength: 10}
-	if f := rule.Apply(content, cfg); len(f) != 0 {
-		t.Fatalf("expected no findings when code blocks ignored, got %d", len(f))
+	if n := len(apply(t, cfg, content)); n != 0 {
+		t.Fatalf("expected no findings when code blocks ignored, got %d", n)
 	}
 	cfg.CodeBlocks = true
-	if f := rule.Apply(content, cfg); len(f) != 1 {
-		t.Fatalf("expected finding when code blocks checked, got %d", len(f))
+	if n := len(apply(t, cfg, content)); n != 1 {
+		t.Fatalf("expected finding when code blocks checked, got %d", n)
+	}
+
+	// Fence lines are never checked, only the code between them.
+	content = "```markdown title=\"a long info string\"\nshort\n````````````\n"
+	if f := apply(t, cfg, content); len(f) != 0 {
+		t.Fatalf("expected fence lines to be skipped, got %+v", f)
 	}
 }
 
 func TestMD1000Rule_TablesOption(t *testing.T) {
-	rule, _ := engine.GetRule("MD1000")
 	content := "|h1|h2|\n|-|-|\n| longlongline |ok|\n"
 	cfg := md1000.Config{LineLength: 10}
-	if f := rule.Apply(content, cfg); len(f) != 0 {
-		t.Fatalf("expected no findings when tables ignored, got %d", len(f))
+	if n := len(apply(t, cfg, content)); n != 0 {
+		t.Fatalf("expected no findings when tables ignored, got %d", n)
 	}
 	cfg.Tables = true
-	if f := rule.Apply(content, cfg); len(f) != 1 {
-		t.Fatalf("expected finding when tables checked, got %d", len(f))
+	if n := len(apply(t, cfg, content)); n != 1 {
+		t.Fatalf("expected finding when tables checked, got %d", n)
 	}
 }
 
 func TestMD1000Rule_Boundary(t *testing.T) {
-	rule, _ := engine.GetRule("MD1000")
-	cfg := md1000.Config{LineLength: 10}
 	content := "0123456789\n01234567890\n"
-	f := rule.Apply(content, cfg)
+	f := apply(t, md1000.Config{LineLength: 10}, content)
 	if len(f) != 1 {
 		t.Fatalf("expected 1 finding, got %d", len(f))
 	}
@@ -61,3 +72,30 @@ func TestMD1000Rule_Boundary(t *testing.T) {
 		t.Fatalf("expected finding on line 2, got %d", f[0].Line)
 	}
 }
+
+func TestMD1000Rule_CRLF(t *testing.T) {
+	content := "0123456789\r\n01234567890\r\n"
+	f := apply(t, md1000.Config{LineLength: 10}, content)
+	if len(f) != 1 {
+		t.Fatalf("expected 1 finding, got %d", len(f))
+	}
+	if got := f[0]; got.Line != 2 || got.Column != 11 || got.EndColumn != 12 || got.Length != 1 {
+		t.Fatalf("unexpected range: %+v", got)
+	}
+}
+
+func TestMD1000Rule_Range(t *testing.T) {
+	content := "short\nnaïve café line\n"
+	f := apply(t, md1000.Config{LineLength: 10}, content)
+	if len(f) != 1 {
+		t.Fatalf("expected 1 finding, got %d", len(f))
+	}
+	// "naïve café" is ten characters but twelve bytes.
+	got := f[0]
+	if got.Column != 13 || got.EndLine != 2 || got.EndColumn != 18 || got.Offset != 18 || got.Length != 5 {
+		t.Fatalf("unexpected range: %+v", got)
+	}
+	if s := content[got.Offset : got.Offset+got.Length]; s != " line" {
+		t.Fatalf("range covers %q", s)
+	}
+}
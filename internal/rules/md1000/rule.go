@@ -5,101 +5,103 @@ package md1000
 
 import (
 	"fmt"
-	"regexp"
 	"strings"
 	"unicode/utf8"
 
 	"github.com/asymmetric-effort/mdlint/internal/engine"
+	"github.com/asymmetric-effort/mdlint/internal/findings"
+	"github.com/asymmetric-effort/mdlint/internal/parser"
 )
 
 // Config configures the MD1000 rule.
 type Config struct {
-	LineLength int  // Maximum allowed line length. Defaults to 80.
-	CodeBlocks bool // Whether to enforce inside fenced code blocks.
-	Tables     bool // Whether to enforce inside tables.
+	LineLength int  `yaml:"line_length" desc:"Maximum allowed line length."`
+	CodeBlocks bool `yaml:"code_blocks" desc:"Check lines inside fenced code blocks."`
+	Tables     bool `yaml:"tables" desc:"Check lines inside tables."`
 }
 
 // Rule implements the MD1000 maximum line length rule.
 type Rule struct{}
 
-// ensure Rule satisfies engine.Rule.
-var _ engine.Rule = Rule{}
+// ensure Rule satisfies engine.Rule and engine.Configurable.
+var (
+	_ engine.Rule         = Rule{}
+	_ engine.Configurable = Rule{}
+)
 
 const defaultLineLength = 80
 
 // init registers the rule in the engine registry.
 func init() {
-	engine.RegisterRule(Rule{})
+	engine.Register(Rule{})
 }
 
 // ID returns the rule identifier.
 func (Rule) ID() string { return "MD1000" }
 
-// Apply checks the supplied Markdown content against the configured maximum
-// line length and returns any findings.
-func (Rule) Apply(content string, cfg any) []engine.Finding {
-	opts := Config{LineLength: defaultLineLength}
-	if c, ok := cfg.(Config); ok {
-		if c.LineLength > 0 {
-			opts.LineLength = c.LineLength
-		}
-		opts.CodeBlocks = c.CodeBlocks
-		opts.Tables = c.Tables
-	}
+// Name returns the human readable rule name.
+func (Rule) Name() string { return "Maximum Line Length" }
 
-	lines := strings.Split(content, "\n")
-	findings := []engine.Finding{}
+// DefaultSeverity returns the severity used unless configured otherwise.
+func (Rule) DefaultSeverity() findings.Severity { return findings.Warning }
 
-	inCode := false
-	inTable := false
-	for i := 0; i < len(lines); i++ {
-		line := lines[i]
-		trimmed := strings.TrimSpace(line)
+// DefaultOptions returns the default rule configuration.
+func (Rule) DefaultOptions() any { return Config{LineLength: defaultLineLength} }
 
-		// Toggle fenced code blocks.
-		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
-			inCode = !inCode
-			// Fences themselves are ignored.
-			continue
-		}
+// Apply checks the supplied Markdown document against the configured maximum
+// line length and returns any findings.
+func (Rule) Apply(doc *parser.Document, cfg engine.RuleConfig) ([]findings.Finding, error) {
+	opts, _ := cfg.Options.(Config)
+	if opts.LineLength <= 0 {
+		opts.LineLength = defaultLineLength
+	}
 
-		// Detect start of a table.
-		if !inCode && isTableHeader(i, lines) {
-			inTable = true
+	result := []findings.Finding{}
+
+	inTable := map[int]bool{}
+	for _, t := range doc.Tables() {
+		for n := t.Start.Line; n <= t.End.Line; n++ {
+			inTable[n] = true
 		}
-		// Detect end of a table.
-		if inTable && (trimmed == "" || !strings.Contains(line, "|")) {
-			inTable = false
+	}
+	// Fences themselves are ignored, even when code blocks are checked.
+	fence := map[int]bool{}
+	for _, fb := range doc.FencedBlocks() {
+		fence[fb.Start.Line] = true
+		if fb.Closed {
+			fence[fb.End.Line] = true
 		}
+	}
+
+	for n := 1; n <= doc.LineCount(); n++ {
+		line := strings.TrimSuffix(string(doc.Line(n)), "\r")
+		inCode := doc.InCodeBlock(n)
 
-		if (!opts.CodeBlocks && inCode) || (!opts.Tables && inTable) {
+		if fence[n] || (!opts.CodeBlocks && inCode) || (!opts.Tables && inTable[n]) {
 			continue
 		}
 
 		if utf8.RuneCountInString(line) > opts.LineLength {
-			findings = append(findings, engine.Finding{
+			// The range covers the characters past the limit.
+			start := doc.LineOffset(n) + runeOffset(line, opts.LineLength)
+			end := doc.LineOffset(n) + len(line)
+			result = append(result, engine.Locate(findings.Finding{
 				Rule:    "MD1000",
-				Line:    i + 1,
-				Column:  opts.LineLength + 1,
 				Message: fmt.Sprintf("Line exceeds maximum length of %d characters", opts.LineLength),
-			})
+			}, doc.Span(start, end)))
 		}
 	}
 
-	return findings
+	return result, nil
 }
 
-var tableSepRE = regexp.MustCompile(`^\s*\|?\s*[:\-]+[-\s:|]*\|`)
-
-// isTableHeader reports whether the line at index i begins a Markdown table.
-func isTableHeader(i int, lines []string) bool {
-	line := lines[i]
-	if !strings.Contains(line, "|") {
-		return false
-	}
-	if i+1 >= len(lines) {
-		return false
+// runeOffset returns the byte offset of the n-th rune (0-based) of s.
+func runeOffset(s string, n int) int {
+	for i := range s {
+		if n == 0 {
+			return i
+		}
+		n--
 	}
-	next := strings.TrimSpace(lines[i+1])
-	return tableSepRE.MatchString(next)
+	return len(s)
 }
@@ -4,77 +4,71 @@
 package md1100
 
 import (
-	"bytes"
-
-	"github.com/yuin/goldmark"
-	"github.com/yuin/goldmark/ast"
-	"github.com/yuin/goldmark/text"
+	"github.com/asymmetric-effort/mdlint/internal/engine"
+	"github.com/asymmetric-effort/mdlint/internal/findings"
+	"github.com/asymmetric-effort/mdlint/internal/parser"
 )
 
 // Config configures the MD1100 rule.
 type Config struct {
 	// Exclude lists section headings to ignore during validation.
-	Exclude []string
+	Exclude []string `yaml:"exclude" desc:"Section headings to ignore."`
 }
 
-// Finding represents a heading level violation.
-type Finding struct {
-	// Line is the line number of the offending heading.
-	Line int
-	// Message describes the violation.
-	Message string
-}
+// Rule reports headings that increase by more than one level at a time.
+type Rule struct{}
+
+func init() { engine.Register(Rule{}) }
+
+// ID returns the rule identifier.
+func (Rule) ID() string { return "MD1100" }
+
+// Name returns the human readable rule name.
+func (Rule) Name() string { return "Sequential Heading Levels" }
+
+// DefaultSeverity returns the severity used unless configured otherwise.
+func (Rule) DefaultSeverity() findings.Severity { return findings.Error }
 
-// CheckSequentialHeadings scans the Markdown document and reports headings that
-// increase by more than one level at a time. Sections whose heading text matches
-// cfg.Exclude are skipped entirely.
-func CheckSequentialHeadings(src []byte, cfg Config) []Finding {
-	md := goldmark.New()
-	root := md.Parser().Parse(text.NewReader(src))
+// DefaultOptions returns the default rule configuration.
+func (Rule) DefaultOptions() any { return Config{} }
 
-	var findings []Finding
+// Apply scans the document and reports headings that increase by more than
+// one level at a time. Sections whose heading text matches Config.Exclude are
+// skipped entirely.
+func (Rule) Apply(doc *parser.Document, cfg engine.RuleConfig) ([]findings.Finding, error) {
+	opts, _ := cfg.Options.(Config)
+
+	var result []findings.Finding
 	var prevLevel int
 	var skipLevel int
 	var skipping bool
 
-	ast.Walk(root, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
-		heading, ok := n.(*ast.Heading)
-		if !ok || !entering {
-			return ast.WalkContinue, nil
-		}
-
-		title := string(heading.Text(src))
-		level := heading.Level
-
+	for _, h := range doc.Headings() {
 		if skipping {
-			if level <= skipLevel {
+			if h.Level <= skipLevel {
 				skipping = false
 			} else {
-				return ast.WalkContinue, nil
+				continue
 			}
 		}
 
-		if contains(cfg.Exclude, title) {
+		if contains(opts.Exclude, h.Text) {
 			skipping = true
-			skipLevel = level
-			prevLevel = level
-			return ast.WalkContinue, nil
+			skipLevel = h.Level
+			prevLevel = h.Level
+			continue
 		}
 
-		if prevLevel != 0 && level > prevLevel+1 {
-			seg := heading.Lines().At(0)
-			line := bytes.Count(src[:seg.Start], []byte("\n")) + 1
-			findings = append(findings, Finding{
-				Line:    line,
+		if prevLevel != 0 && h.Level > prevLevel+1 {
+			result = append(result, engine.Locate(findings.Finding{
 				Message: "heading level should only increment by one level at a time",
-			})
+			}, h.Span))
 		}
 
-		prevLevel = level
-		return ast.WalkContinue, nil
-	})
+		prevLevel = h.Level
+	}
 
-	return findings
+	return result, nil
 }
 
 func contains(list []string, s string) bool {
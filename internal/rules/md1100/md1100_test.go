@@ -3,25 +3,38 @@
 // Copyright (c) 2025 Sam Caldwell
 package md1100
 
-import "testing"
+import (
+	"testing"
 
-func TestCheckSequentialHeadingsValid(t *testing.T) {
-	src := []byte("# H1\n## H2\n### H3\n")
-	if f := CheckSequentialHeadings(src, Config{}); len(f) != 0 {
+	"github.com/asymmetric-effort/mdlint/internal/engine"
+	"github.com/asymmetric-effort/mdlint/internal/findings"
+	"github.com/asymmetric-effort/mdlint/internal/parser"
+)
+
+func check(t *testing.T, src string, cfg Config) []findings.Finding {
+	t.Helper()
+	f, err := Rule{}.Apply(parser.Parse("", []byte(src)), engine.RuleConfig{Options: cfg})
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	return f
+}
+
+func TestSequentialHeadingsValid(t *testing.T) {
+	if f := check(t, "# H1\n## H2\n### H3\n", Config{}); len(f) != 0 {
 		t.Fatalf("expected no findings, got %d", len(f))
 	}
 }
 
-func TestCheckSequentialHeadingsInvalid(t *testing.T) {
-	src := []byte("# H1\n### H3\n")
-	if f := CheckSequentialHeadings(src, Config{}); len(f) == 0 {
-		t.Fatalf("expected findings for non-sequential headings")
+func TestSequentialHeadingsInvalid(t *testing.T) {
+	f := check(t, "# H1\n### H3\n", Config{})
+	if len(f) != 1 || f[0].Line != 2 {
+		t.Fatalf("expected a finding on line 2, got %+v", f)
 	}
 }
 
-func TestCheckSequentialHeadingsExclude(t *testing.T) {
-	src := []byte("# Intro\n### Jump\n")
-	if f := CheckSequentialHeadings(src, Config{Exclude: []string{"Intro"}}); len(f) != 0 {
+func TestSequentialHeadingsExclude(t *testing.T) {
+	if f := check(t, "# Intro\n### Jump\n", Config{Exclude: []string{"Intro"}}); len(f) != 0 {
 		t.Fatalf("expected exclusion to skip findings, got %d", len(f))
 	}
 }
@@ -3,8 +3,151 @@
 
 package rules
 
-// RuleID: MD1100
+// RuleID: MD1101
 // Name: Consistent Heading Style
 // Summary: Enforces atx, setext, or consistent heading style within a document.
 // Severity: error
 // Options: style, allow_mixed
+//
+// The specification lists this rule as MD1100, an ID already used by the
+// sequential heading levels rule, so it is registered as MD1101.
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/asymmetric-effort/mdlint/internal/config"
+	"github.com/asymmetric-effort/mdlint/internal/engine"
+	"github.com/asymmetric-effort/mdlint/internal/findings"
+	"github.com/asymmetric-effort/mdlint/internal/parser"
+)
+
+// HeadingStyleConfig configures the heading style rule.
+type HeadingStyleConfig struct {
+	// Style is the required heading style: "atx", "setext" or
+	// "consistent", which requires every heading to match the first one.
+	// Setext headings only exist for levels 1 and 2, so deeper headings are
+	// always expected to use ATX.
+	Style string `yaml:"style" enum:"atx,setext,consistent" desc:"Required heading style; consistent requires every heading to match the first one."`
+	// AllowMixed treats closed ATX headings ("# Title #") as ATX headings.
+	// Otherwise the closing sequence is reported.
+	AllowMixed bool `yaml:"allow_mixed" desc:"Accept closed ATX headings such as '# Title #'."`
+}
+
+// HeadingStyle implements MD1101, reporting headings whose style differs from
+// the configured one and suggesting the converted heading.
+type HeadingStyle struct{}
+
+func init() { engine.Register(HeadingStyle{}) }
+
+// ID returns the rule identifier.
+func (HeadingStyle) ID() string { return "MD1101" }
+
+// Name returns the human readable rule name.
+func (HeadingStyle) Name() string { return "Consistent Heading Style" }
+
+// DefaultSeverity returns the severity used unless configured otherwise.
+func (HeadingStyle) DefaultSeverity() findings.Severity { return findings.Error }
+
+// Fixable reports that heading conversions may be applied automatically.
+func (HeadingStyle) Fixable() bool { return true }
+
+// DefaultOptions returns the default rule configuration.
+func (HeadingStyle) DefaultOptions() any { return HeadingStyleConfig{Style: "consistent"} }
+
+// OptionsFrom returns the rule options set by the heading section of the
+// configuration.
+func (r HeadingStyle) OptionsFrom(lint config.Config) any {
+	opts := r.DefaultOptions().(HeadingStyleConfig)
+	if lint.Heading.Style != "" {
+		opts.Style = lint.Heading.Style
+	}
+	if lint.Heading.AllowMixed != nil {
+		opts.AllowMixed = *lint.Heading.AllowMixed
+	}
+	return opts
+}
+
+// Apply checks every heading against the configured style.
+func (HeadingStyle) Apply(doc *parser.Document, cfg engine.RuleConfig) ([]findings.Finding, error) {
+	opts, _ := cfg.Options.(HeadingStyleConfig)
+	style := parser.StyleATX
+	switch opts.Style {
+	case "setext":
+		style = parser.StyleSetext
+	case "", "consistent":
+		outline := doc.Outline()
+		if len(outline) == 0 {
+			return nil, nil
+		}
+		style = outline[0].Style
+		if style == parser.StyleATXClosed && opts.AllowMixed {
+			style = parser.StyleATX
+		}
+	}
+
+	var result []findings.Finding
+	for _, h := range doc.Outline() {
+		want := style
+		if want == parser.StyleSetext && h.Level > 2 {
+			want = parser.StyleATX
+		}
+		got := h.Style
+		if opts.AllowMixed && got == parser.StyleATXClosed {
+			got = parser.StyleATX
+		}
+		if got == want {
+			continue
+		}
+		f := engine.Locate(findings.Finding{
+			Message: fmt.Sprintf("heading style should be %s, found %s", want, h.Style),
+		}, h.Span)
+		if text, ok := convertHeading(doc, h, want); ok {
+			f.Suggestions = []findings.SuggestedFix{{
+				Label: fmt.Sprintf("Convert to %s heading", want),
+				Edits: []findings.Edit{engine.Replace(h.Span, text)},
+			}}
+		}
+		result = append(result, f)
+	}
+	return result, nil
+}
+
+// convertHeading returns the source of h rewritten in the given style. It
+// reports false when the heading cannot be converted safely, such as an
+// empty heading or a setext heading nested in a container whose line
+// prefixes would have to be repeated.
+func convertHeading(doc *parser.Document, h parser.OutlineEntry, style parser.HeadingStyle) (string, bool) {
+	lines := h.Node.Lines()
+	if lines.Len() == 0 {
+		return "", false
+	}
+	parts := make([]string, lines.Len())
+	for i := 0; i < lines.Len(); i++ {
+		seg := lines.At(i)
+		parts[i] = strings.TrimSpace(string(doc.Source[seg.Start:seg.Stop]))
+	}
+	content := strings.Join(parts, " ")
+	marks := strings.Repeat("#", h.Level)
+	switch style {
+	case parser.StyleATX:
+		return marks + " " + content, true
+	case parser.StyleATXClosed:
+		return marks + " " + content + " " + marks, true
+	case parser.StyleSetext:
+		if h.Start.Column != 1 {
+			return "", false
+		}
+		underline := "="
+		if h.Level == 2 {
+			underline = "-"
+		}
+		n := utf8.RuneCountInString(content)
+		if n < 3 {
+			n = 3
+		}
+		return content + "\n" + strings.Repeat(underline, n), true
+	}
+	return "", false
+}
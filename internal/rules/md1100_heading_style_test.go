@@ -0,0 +1,78 @@
+// Copyright (c) 2024 MdLint contributors.
+// SPDX-License-Identifier: MIT
+
+package rules
+
+import (
+	"testing"
+
+	"github.com/asymmetric-effort/mdlint/internal/engine"
+	"github.com/asymmetric-effort/mdlint/internal/fix"
+	"github.com/asymmetric-effort/mdlint/internal/parser"
+)
+
+func TestHeadingStyle(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		cfg     HeadingStyleConfig
+		lines   []int
+		fixed   string
+	}{
+		{
+			name:    "consistent with first atx",
+			content: "# One\n\nTwo *em*\n---\n\n### Three ###\n",
+			cfg:     HeadingStyleConfig{Style: "consistent"},
+			lines:   []int{3, 6},
+			fixed:   "# One\n\n## Two *em*\n\n### Three\n",
+		},
+		{
+			name:    "allow mixed closed atx",
+			content: "# One\n\n## Two ##\n",
+			cfg:     HeadingStyleConfig{Style: "atx", AllowMixed: true},
+		},
+		{
+			name:    "setext keeps deep atx",
+			content: "# One\n\n## Two\n\n### Three\n",
+			cfg:     HeadingStyleConfig{Style: "setext"},
+			lines:   []int{1, 3},
+			fixed:   "One\n===\n\nTwo\n---\n\n### Three\n",
+		},
+		{
+			name:    "multi-line setext to atx",
+			content: "Title\nwraps\n=====\n\n## Next\n",
+			cfg:     HeadingStyleConfig{Style: "atx"},
+			lines:   []int{1},
+			fixed:   "# Title wraps\n\n## Next\n",
+		},
+		{
+			name:    "nested setext conversion is not suggested",
+			content: "Top\n===\n\n> # Quoted\n",
+			cfg:     HeadingStyleConfig{Style: "setext"},
+			lines:   []int{4},
+			fixed:   "Top\n===\n\n> # Quoted\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := HeadingStyle{}.Apply(parser.Parse("", []byte(tt.content)), engine.RuleConfig{Options: tt.cfg})
+			if err != nil {
+				t.Fatalf("apply: %v", err)
+			}
+			if len(got) != len(tt.lines) {
+				t.Fatalf("got %d findings, want %d: %+v", len(got), len(tt.lines), got)
+			}
+			for i, f := range got {
+				if f.Line != tt.lines[i] {
+					t.Errorf("finding %d line = %d, want %d", i, f.Line, tt.lines[i])
+				}
+			}
+			if tt.fixed == "" {
+				return
+			}
+			if out, _ := fix.Apply([]byte(tt.content), got); string(out) != tt.fixed {
+				t.Fatalf("fixed output:\n%s\nwant:\n%s", out, tt.fixed)
+			}
+		})
+	}
+}
@@ -10,16 +10,20 @@ import (
 	"strings"
 
 	"github.com/alecthomas/chroma/v2/lexers"
-	"github.com/yuin/goldmark"
-	"github.com/yuin/goldmark/ast"
-	"github.com/yuin/goldmark/text"
+
+	"github.com/asymmetric-effort/mdlint/internal/engine"
+	"github.com/asymmetric-effort/mdlint/internal/findings"
+	"github.com/asymmetric-effort/mdlint/internal/parser"
 )
 
 // Config configures the MD1400 rule.
 type Config struct {
 	// Allowed lists the permitted language identifiers. If empty, any language
 	// recognized by Chroma is allowed.
-	Allowed []string
+	Allowed []string `yaml:"allowed" desc:"Permitted language identifiers; any language known to Chroma when empty."`
+	// Default is the language suggested for fences missing one. No fix is
+	// suggested when it is empty.
+	Default string `yaml:"default" desc:"Language suggested for fences missing one."`
 }
 
 // allowedMap returns a set of allowed language identifiers in lowercase for
@@ -32,47 +36,66 @@ func (c Config) allowedMap() map[string]struct{} {
 	return m
 }
 
-// Finding reports a code fence language issue.
-type Finding struct {
-	// Line is the line number where the fence starts.
-	Line int
-	// Message describes the violation.
-	Message string
-}
+// Rule verifies that fenced code blocks specify allowed and recognized
+// language identifiers.
+type Rule struct{}
 
-// CheckCodeBlockLanguages verifies that fenced code blocks specify allowed and
-// recognized language identifiers.
-func CheckCodeBlockLanguages(src []byte, cfg Config) []Finding {
-	md := goldmark.New()
-	root := md.Parser().Parse(text.NewReader(src))
+func init() { engine.Register(Rule{}) }
 
-	allowed := cfg.allowedMap()
+// ID returns the rule identifier.
+func (Rule) ID() string { return "MD1400" }
 
-	var findings []Finding
-	ast.Walk(root, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
-		block, ok := n.(*ast.FencedCodeBlock)
-		if !ok || !entering {
-			return ast.WalkContinue, nil
-		}
-		lang := strings.ToLower(string(block.Language(src)))
-		seg := block.Lines().At(0)
-		line := bytes.Count(src[:seg.Start], []byte("\n"))
+// Name returns the human readable rule name.
+func (Rule) Name() string { return "Code Fence Language" }
+
+// DefaultSeverity returns the severity used unless configured otherwise.
+func (Rule) DefaultSeverity() findings.Severity { return findings.Warning }
+
+// DefaultOptions returns the default rule configuration.
+func (Rule) DefaultOptions() any { return Config{Default: defaultLanguage} }
+
+// defaultLanguage is the language suggested for fences without one.
+const defaultLanguage = "text"
 
+// Fixable reports that the default language may be added automatically.
+func (Rule) Fixable() bool { return true }
+
+// Apply reports fenced code blocks with missing, unknown or disallowed
+// languages.
+func (Rule) Apply(doc *parser.Document, cfg engine.RuleConfig) ([]findings.Finding, error) {
+	opts, _ := cfg.Options.(Config)
+	allowed := opts.allowedMap()
+
+	var result []findings.Finding
+	for _, block := range doc.FencedBlocks() {
+		// Report against the opening fence line, including its info string.
+		line := doc.Line(block.Start.Line)
+		end := doc.LineOffset(block.Start.Line) + len(bytes.TrimRight(line, " \t\r"))
+		opening := doc.Span(block.Start.Offset, end)
+		report := func(msg string) {
+			result = append(result, engine.Locate(findings.Finding{Message: msg}, opening))
+		}
+		lang := strings.ToLower(block.Language)
 		if lang == "" {
-			findings = append(findings, Finding{Line: line, Message: "code fence is missing a language identifier"})
-			return ast.WalkContinue, nil
+			f := engine.Locate(findings.Finding{Message: "code fence is missing a language identifier"}, opening)
+			if opts.Default != "" && strings.TrimSpace(block.Info) == "" {
+				f.Suggestions = []findings.SuggestedFix{{
+					Label: fmt.Sprintf("Set language to %q", opts.Default),
+					Edits: []findings.Edit{engine.Replace(doc.Span(block.Fence.End.Offset, end), opts.Default)},
+				}}
+			}
+			result = append(result, f)
+			continue
 		}
 		if lexers.Get(lang) == nil {
-			findings = append(findings, Finding{Line: line, Message: fmt.Sprintf("unknown language %q", lang)})
-			return ast.WalkContinue, nil
+			report(fmt.Sprintf("unknown language %q", lang))
+			continue
 		}
 		if len(allowed) > 0 {
 			if _, ok := allowed[lang]; !ok {
-				findings = append(findings, Finding{Line: line, Message: fmt.Sprintf("language %q not allowed", lang)})
+				report(fmt.Sprintf("language %q not allowed", lang))
 			}
 		}
-		return ast.WalkContinue, nil
-	})
-
-	return findings
+	}
+	return result, nil
 }
@@ -2,26 +2,33 @@
 
 package md1400
 
-import "testing"
+import (
+	"reflect"
+	"testing"
 
-func TestCheckCodeBlockLanguages(t *testing.T) {
+	"github.com/asymmetric-effort/mdlint/internal/engine"
+	"github.com/asymmetric-effort/mdlint/internal/findings"
+	"github.com/asymmetric-effort/mdlint/internal/parser"
+)
+
+func TestCodeBlockLanguages(t *testing.T) {
 	tests := []struct {
 		name string
 		src  string
 		cfg  Config
-		want []Finding
+		want []findings.Finding
 	}{
 		{
 			name: "unknown language",
 			src:  "```foobar\ncode\n```\n",
 			cfg:  Config{},
-			want: []Finding{{Line: 1, Message: "unknown language \"foobar\""}},
+			want: []findings.Finding{{Line: 1, Column: 1, EndLine: 1, EndColumn: 10, Length: 9, Message: "unknown language \"foobar\""}},
 		},
 		{
 			name: "missing language",
 			src:  "```\ncode\n```\n",
 			cfg:  Config{},
-			want: []Finding{{Line: 1, Message: "code fence is missing a language identifier"}},
+			want: []findings.Finding{{Line: 1, Column: 1, EndLine: 1, EndColumn: 4, Length: 3, Message: "code fence is missing a language identifier"}},
 		},
 		{
 			name: "allowed language",
@@ -33,20 +40,57 @@ func TestCheckCodeBlockLanguages(t *testing.T) {
 			name: "disallowed language",
 			src:  "```python\ncode\n```\n",
 			cfg:  Config{Allowed: []string{"go"}},
-			want: []Finding{{Line: 1, Message: "language \"python\" not allowed"}},
+			want: []findings.Finding{{Line: 1, Column: 1, EndLine: 1, EndColumn: 10, Length: 9, Message: "language \"python\" not allowed"}},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := CheckCodeBlockLanguages([]byte(tt.src), tt.cfg)
+			got, err := Rule{}.Apply(parser.Parse("", []byte(tt.src)), engine.RuleConfig{Options: tt.cfg})
+			if err != nil {
+				t.Fatalf("apply: %v", err)
+			}
 			if len(got) != len(tt.want) {
 				t.Fatalf("got %d findings, want %d", len(got), len(tt.want))
 			}
 			for i := range tt.want {
-				if got[i] != tt.want[i] {
+				if !reflect.DeepEqual(got[i], tt.want[i]) {
 					t.Fatalf("finding %d: got %+v, want %+v", i, got[i], tt.want[i])
 				}
 			}
 		})
 	}
 }
+
+func TestCodeBlockLanguagesPosition(t *testing.T) {
+	src := "# Title\n\n- item\n\n  ```\n  code\n  ```\n\n```\n```\n"
+	got, err := Rule{}.Apply(parser.Parse("", []byte(src)), engine.RuleConfig{})
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d findings, want 2", len(got))
+	}
+	if got[0].Line != 5 || got[0].Column != 3 || got[1].Line != 9 || got[1].Column != 1 {
+		t.Fatalf("unexpected positions: %+v", got)
+	}
+}
+
+func TestCodeBlockLanguagesDefault(t *testing.T) {
+	src := "```\ncode\n```\n\n~~~~ \nmore\n~~~~\n"
+	got, err := Rule{}.Apply(parser.Parse("", []byte(src)), engine.RuleConfig{Options: Rule{}.DefaultOptions()})
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d findings, want 2", len(got))
+	}
+	want := []findings.Edit{
+		{Line: 1, Column: 4, EndLine: 1, EndColumn: 4, Offset: 3, Length: 0, NewText: "text"},
+		{Line: 5, Column: 5, EndLine: 5, EndColumn: 5, Offset: 18, Length: 0, NewText: "text"},
+	}
+	for i, f := range got {
+		if len(f.Suggestions) != 1 || !reflect.DeepEqual(f.Suggestions[0].Edits, want[i:i+1]) {
+			t.Fatalf("finding %d: unexpected suggestions %+v", i, f.Suggestions)
+		}
+	}
+}
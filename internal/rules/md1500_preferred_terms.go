@@ -8,3 +8,122 @@ package rules
 // Summary: Enforces consistent terminology based on project vocabulary mappings.
 // Severity: warning
 // Options: terms
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/asymmetric-effort/mdlint/internal/engine"
+	"github.com/asymmetric-effort/mdlint/internal/findings"
+	"github.com/asymmetric-effort/mdlint/internal/parser"
+)
+
+// PreferredTermsConfig configures the preferred terms rule.
+type PreferredTermsConfig struct {
+	// Terms maps a discouraged term to its preferred replacement. Terms are
+	// matched case-insensitively on word boundaries.
+	Terms map[string]string `yaml:"terms" desc:"Preferred replacement by discouraged term, matched case-insensitively."`
+}
+
+// defaultTerms is the vocabulary used when no terms are configured.
+var defaultTerms = map[string]string{
+	"e-mail":   "email",
+	"on-line":  "online",
+	"web site": "website",
+}
+
+// PreferredTerms implements MD1500, reporting discouraged terms in prose and
+// suggesting the preferred variant.
+type PreferredTerms struct{}
+
+func init() { engine.Register(PreferredTerms{}) }
+
+// ID returns the rule identifier.
+func (PreferredTerms) ID() string { return "MD1500" }
+
+// Name returns the human readable rule name.
+func (PreferredTerms) Name() string { return "Consistency (Preferred Terms)" }
+
+// DefaultSeverity returns the severity used unless configured otherwise.
+func (PreferredTerms) DefaultSeverity() findings.Severity { return findings.Warning }
+
+// Fixable reports that preferred term replacements may be applied
+// automatically.
+func (PreferredTerms) Fixable() bool { return true }
+
+// DefaultOptions returns the default rule configuration.
+func (PreferredTerms) DefaultOptions() any {
+	terms := make(map[string]string, len(defaultTerms))
+	for k, v := range defaultTerms {
+		terms[k] = v
+	}
+	return PreferredTermsConfig{Terms: terms}
+}
+
+// Apply scans the prose of the document for discouraged terms. Code, HTML,
+// URLs and front matter are never checked.
+func (PreferredTerms) Apply(doc *parser.Document, cfg engine.RuleConfig) ([]findings.Finding, error) {
+	opts, _ := cfg.Options.(PreferredTermsConfig)
+	if len(opts.Terms) == 0 {
+		return nil, nil
+	}
+	// Try longer terms first so that "web site" wins over "site".
+	terms := make([]string, 0, len(opts.Terms))
+	for t := range opts.Terms {
+		terms = append(terms, t)
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		if len(terms[i]) != len(terms[j]) {
+			return len(terms[i]) > len(terms[j])
+		}
+		return terms[i] < terms[j]
+	})
+	quoted := make([]string, len(terms))
+	for i, t := range terms {
+		quoted[i] = regexp.QuoteMeta(t)
+	}
+	re, err := regexp.Compile(`(?i)\b(?:` + strings.Join(quoted, "|") + `)\b`)
+	if err != nil {
+		return nil, err
+	}
+	lower := make(map[string]string, len(opts.Terms))
+	for t, p := range opts.Terms {
+		lower[strings.ToLower(t)] = p
+	}
+
+	var result []findings.Finding
+	for _, seg := range doc.Prose(parser.ScopeAll) {
+		for _, m := range re.FindAllStringIndex(seg.Text, -1) {
+			found := seg.Text[m[0]:m[1]]
+			preferred := matchCase(lower[strings.ToLower(found)], found)
+			if preferred == found {
+				continue
+			}
+			span := doc.Span(seg.Start.Offset+m[0], seg.Start.Offset+m[1])
+			result = append(result, engine.Locate(findings.Finding{
+				Message: fmt.Sprintf("use %q instead of %q", preferred, found),
+				Suggestions: []findings.SuggestedFix{{
+					Label: fmt.Sprintf("Replace with %q", preferred),
+					Edits: []findings.Edit{engine.Replace(span, preferred)},
+				}},
+			}, span))
+		}
+	}
+	return result, nil
+}
+
+// matchCase capitalizes the first letter of preferred when found starts with
+// an upper case letter, so that terms at the start of a sentence stay
+// capitalized.
+func matchCase(preferred, found string) string {
+	f, _ := utf8.DecodeRuneInString(found)
+	p, size := utf8.DecodeRuneInString(preferred)
+	if !unicode.IsUpper(f) || unicode.IsUpper(p) {
+		return preferred
+	}
+	return string(unicode.ToUpper(p)) + preferred[size:]
+}
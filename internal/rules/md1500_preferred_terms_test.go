@@ -0,0 +1,57 @@
+// Copyright (c) 2024 MdLint contributors.
+// SPDX-License-Identifier: MIT
+
+package rules
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/asymmetric-effort/mdlint/internal/engine"
+	"github.com/asymmetric-effort/mdlint/internal/findings"
+	"github.com/asymmetric-effort/mdlint/internal/parser"
+)
+
+func TestPreferredTerms(t *testing.T) {
+	content := "# E-mail setup\n\nSend an e-mail from our Web Site.\n\n" +
+		"`e-mail` in code and <https://e-mail.example> are ignored.\n\n```\ne-mail\n```\n"
+	got, err := PreferredTerms{}.Apply(parser.Parse("", []byte(content)), engine.RuleConfig{Options: PreferredTerms{}.DefaultOptions()})
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	var messages []string
+	for _, f := range got {
+		messages = append(messages, f.Message)
+	}
+	want := []string{
+		`use "Email" instead of "E-mail"`,
+		`use "email" instead of "e-mail"`,
+		`use "Website" instead of "Web Site"`,
+	}
+	if !reflect.DeepEqual(messages, want) {
+		t.Fatalf("unexpected findings: %q", messages)
+	}
+
+	f := got[1]
+	if f.Line != 3 || f.Column != 9 || f.EndColumn != 15 {
+		t.Fatalf("unexpected range: %+v", f)
+	}
+	fix := []findings.SuggestedFix{{
+		Label: `Replace with "email"`,
+		Edits: []findings.Edit{{Line: 3, Column: 9, EndLine: 3, EndColumn: 15, Offset: 24, Length: 6, NewText: "email"}},
+	}}
+	if !reflect.DeepEqual(f.Suggestions, fix) {
+		t.Fatalf("unexpected suggestions: %+v", f.Suggestions)
+	}
+}
+
+func TestPreferredTermsCustom(t *testing.T) {
+	cfg := engine.RuleConfig{Options: PreferredTermsConfig{Terms: map[string]string{"golang": "Go"}}}
+	got, err := PreferredTerms{}.Apply(parser.Parse("", []byte("Written in golang and Go.\n")), cfg)
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if len(got) != 1 || got[0].Suggestions[0].Edits[0].NewText != "Go" {
+		t.Fatalf("unexpected findings: %+v", got)
+	}
+}
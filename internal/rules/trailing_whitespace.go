@@ -4,72 +4,75 @@ package rules
 
 import (
 	"strings"
-)
 
-// Finding represents a rule violation.
-type Finding struct {
-	Line    int
-	Column  int
-	RuleID  string
-	Message string
-}
+	"github.com/asymmetric-effort/mdlint/internal/engine"
+	"github.com/asymmetric-effort/mdlint/internal/findings"
+	"github.com/asymmetric-effort/mdlint/internal/parser"
+)
 
 // TrailingWhitespaceConfig configures the trailing whitespace rule.
 type TrailingWhitespaceConfig struct {
-	// IgnoreCodeBlocks controls whether lines inside fenced code blocks are checked.
-	// When true, lines within fenced code blocks are skipped.
-	IgnoreCodeBlocks bool
+	// IgnoreCodeBlocks controls whether lines inside code blocks are checked.
+	// When true, lines within fenced and indented code blocks are skipped.
+	IgnoreCodeBlocks bool `yaml:"ignore_code_blocks" desc:"Skip lines inside fenced and indented code blocks."`
 }
 
-// CheckTrailingWhitespace scans content for trailing spaces or tabs.
-// It returns a list of findings for lines containing trailing whitespace.
-func CheckTrailingWhitespace(content []byte, cfg TrailingWhitespaceConfig) []Finding {
-	lines := strings.Split(string(content), "\n")
-	var findings []Finding
-	inCode := false
-	var fenceChar rune
-	for i, line := range lines {
-		// Remove trailing carriage return for Windows line endings.
-		line = strings.TrimSuffix(line, "\r")
+// TrailingWhitespace implements MD1800, reporting lines that end in spaces or
+// tabs. Two or more spaces ending a paragraph line other than the last are a
+// hard line break and are not reported.
+type TrailingWhitespace struct{}
 
-		trimmedLeft := strings.TrimLeft(line, " \t")
-		if f, ok := fenceMarker(trimmedLeft); ok {
-			if inCode {
-				if f == fenceChar {
-					inCode = false
-				}
-			} else {
-				inCode = true
-				fenceChar = f
-			}
-			if cfg.IgnoreCodeBlocks {
-				continue
-			}
+func init() { engine.Register(TrailingWhitespace{}) }
+
+// ID returns the rule identifier.
+func (TrailingWhitespace) ID() string { return "MD1800" }
+
+// Name returns the human readable rule name.
+func (TrailingWhitespace) Name() string { return "Trailing Whitespace" }
+
+// DefaultSeverity returns the severity used unless configured otherwise.
+func (TrailingWhitespace) DefaultSeverity() findings.Severity { return findings.Warning }
+
+// Fixable reports that findings carry an edit removing the whitespace. Hard
+// line breaks are never reported, so the edit does not change the rendering.
+func (TrailingWhitespace) Fixable() bool { return true }
+
+// DefaultOptions returns the default rule configuration.
+func (TrailingWhitespace) DefaultOptions() any {
+	return TrailingWhitespaceConfig{}
+}
+
+// Apply scans the document for trailing spaces or tabs. It returns a finding
+// for every line containing trailing whitespace.
+func (TrailingWhitespace) Apply(doc *parser.Document, cfg engine.RuleConfig) ([]findings.Finding, error) {
+	opts, _ := cfg.Options.(TrailingWhitespaceConfig)
+	breaks := map[int]bool{}
+	for _, p := range doc.Paragraphs() {
+		for n := p.Start.Line; n < p.End.Line; n++ {
+			breaks[n] = true
 		}
-		if inCode && cfg.IgnoreCodeBlocks {
+	}
+	var result []findings.Finding
+	for n := 1; n <= doc.LineCount(); n++ {
+		if opts.IgnoreCodeBlocks && doc.InCodeBlock(n) {
 			continue
 		}
-		if len(line) > 0 {
-			last := line[len(line)-1]
-			if last == ' ' || last == '\t' {
-				findings = append(findings, Finding{
-					Line:    i + 1,
-					Column:  len(line),
-					RuleID:  "MD1800",
-					Message: "line has trailing whitespace",
-				})
+		// Remove trailing carriage return for Windows line endings.
+		line := strings.TrimSuffix(string(doc.Line(n)), "\r")
+		if trimmed := strings.TrimRight(line, " \t"); len(trimmed) < len(line) {
+			if ws := line[len(trimmed):]; breaks[n] && len(ws) >= 2 && strings.Trim(ws, " ") == "" {
+				continue
 			}
+			off := doc.LineOffset(n)
+			span := doc.Span(off+len(trimmed), off+len(line))
+			result = append(result, engine.Locate(findings.Finding{
+				Message: "line has trailing whitespace",
+				Suggestions: []findings.SuggestedFix{{
+					Label: "Remove trailing whitespace",
+					Edits: []findings.Edit{engine.Replace(span, "")},
+				}},
+			}, span))
 		}
 	}
-	return findings
-}
-
-func fenceMarker(line string) (rune, bool) {
-	if strings.HasPrefix(line, "```") {
-		return '`', true
-	}
-	if strings.HasPrefix(line, "~~~") {
-		return '~', true
-	}
-	return 0, false
+	return result, nil
 }
@@ -2,9 +2,14 @@
 
 package rules
 
-import "testing"
+import (
+	"testing"
 
-func TestCheckTrailingWhitespace(t *testing.T) {
+	"github.com/asymmetric-effort/mdlint/internal/engine"
+	"github.com/asymmetric-effort/mdlint/internal/parser"
+)
+
+func TestTrailingWhitespace(t *testing.T) {
 	tests := []struct {
 		name    string
 		content string
@@ -23,12 +28,30 @@ func TestCheckTrailingWhitespace(t *testing.T) {
 			cfg:     TrailingWhitespaceConfig{IgnoreCodeBlocks: false},
 			want:    []int{2, 4, 6},
 		},
+		{
+			name:    "code blocks checked by default",
+			content: "```go\ncode  \n```\n",
+			cfg:     TrailingWhitespace{}.DefaultOptions().(TrailingWhitespaceConfig),
+			want:    []int{2},
+		},
 		{
 			name:    "only code block when ignored",
 			content: "```go\ncode  \n```\n",
 			cfg:     TrailingWhitespaceConfig{IgnoreCodeBlocks: true},
 			want:    nil,
 		},
+		{
+			name:    "nested and indented code blocks ignored",
+			content: "- item\n  ```\n  nested  \n\n  ```\n\n>     quoted code \n\ntext \n",
+			cfg:     TrailingWhitespaceConfig{IgnoreCodeBlocks: true},
+			want:    []int{9},
+		},
+		{
+			name:    "hard line breaks kept",
+			content: "first line  \nsecond line \nthird line   \nlast line  \n\n- item  \n  more\n\none\t\ntwo\n",
+			cfg:     TrailingWhitespaceConfig{IgnoreCodeBlocks: true},
+			want:    []int{2, 4, 9},
+		},
 		{
 			name:    "blank line with spaces",
 			content: "line\n   \nnext\n",
@@ -38,7 +61,10 @@ func TestCheckTrailingWhitespace(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := CheckTrailingWhitespace([]byte(tt.content), tt.cfg)
+			got, err := TrailingWhitespace{}.Apply(parser.Parse("", []byte(tt.content)), engine.RuleConfig{Options: tt.cfg})
+			if err != nil {
+				t.Fatalf("apply: %v", err)
+			}
 			if len(got) != len(tt.want) {
 				t.Fatalf("got %d findings, want %d", len(got), len(tt.want))
 			}
@@ -50,3 +76,21 @@ func TestCheckTrailingWhitespace(t *testing.T) {
 		})
 	}
 }
+
+func TestTrailingWhitespaceRange(t *testing.T) {
+	content := "text \t\r\nnext\n"
+	got, err := TrailingWhitespace{}.Apply(parser.Parse("", []byte(content)), engine.RuleConfig{Options: TrailingWhitespaceConfig{}})
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("got %d findings, want 1", len(got))
+	}
+	if f := got[0]; f.Column != 5 || f.EndColumn != 7 || f.Offset != 4 || f.Length != 2 {
+		t.Fatalf("unexpected range: %+v", f)
+	}
+	if s := got[0].Suggestions; len(s) != 1 || len(s[0].Edits) != 1 ||
+		s[0].Edits[0].Offset != 4 || s[0].Edits[0].Length != 2 || s[0].Edits[0].NewText != "" {
+		t.Fatalf("unexpected suggestions: %+v", s)
+	}
+}
@@ -4,6 +4,7 @@ package tests
 
 import (
 	"bytes"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
@@ -60,7 +61,7 @@ func TestCLI_ListRules(t *testing.T) {
 	if err != nil {
 		t.Fatalf("run: %v", err)
 	}
-	if code != 0 || !strings.Contains(out, "MD9000") {
+	if code != 0 || !strings.Contains(out, "MD1000") || !strings.Contains(out, "MD1800") {
 		t.Fatalf("unexpected: code %d output %s", code, out)
 	}
 }
@@ -85,7 +86,401 @@ func TestCLI_ConfigFormat(t *testing.T) {
 	if code != 1 {
 		t.Fatalf("expected exit 1 got %d output %s", code, out)
 	}
-	if !strings.Contains(out, "MD9000") || strings.HasPrefix(strings.TrimSpace(out), "[") {
+	if !strings.Contains(out, "MD1100") || strings.HasPrefix(strings.TrimSpace(out), "[") {
 		t.Fatalf("expected text findings got %s", out)
 	}
 }
+
+// TestCLI_Fix ensures --fix rewrites fixable findings and reports the rest.
+func TestCLI_Fix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "doc.md")
+	src := "# Title\n\nSend an e-mail. \n\n```\ncode\n```\n\n#### Skipped level\n"
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out, code, err := run("--fix", "--format", "text", path)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if code != 1 {
+		t.Fatalf("expected exit 1 got %d output %s", code, out)
+	}
+	if !strings.Contains(out, "3 fixed in 1 file(s), 1 remaining") || !strings.Contains(out, "MD1100") {
+		t.Fatalf("unexpected output %s", out)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# Title\n\nSend an email.\n\n```text\ncode\n```\n\n#### Skipped level\n"
+	if string(got) != want {
+		t.Fatalf("unexpected fixed file:\n%s", got)
+	}
+}
+
+// TestCLI_FixDryRun ensures --fix-dry-run prints a diff and leaves files as
+// they are.
+func TestCLI_FixDryRun(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "doc.md")
+	src := "# Title\n\nSend an e-mail. \n"
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out, code, err := run("--fix-dry-run", path)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if code != 1 {
+		t.Fatalf("expected exit 1 got %d output %s", code, out)
+	}
+	if !strings.Contains(out, "-Send an e-mail. \n+Send an email.\n") || !strings.Contains(out, "2 fix(es) pending in 1 file(s)") {
+		t.Fatalf("unexpected output %s", out)
+	}
+	if got, _ := os.ReadFile(path); string(got) != src {
+		t.Fatalf("dry run modified the file:\n%s", got)
+	}
+
+	patch := filepath.Join(dir, "fix.patch")
+	if _, _, err := run("--fix-dry-run", "--patch-file", patch, path); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	// The file lies outside the working directory, so it keeps its path.
+	if b, err := os.ReadFile(patch); err != nil || !strings.HasPrefix(string(b), "--- "+filepath.ToSlash(path)+"\n") {
+		t.Fatalf("unexpected patch %q: %v", b, err)
+	}
+
+	out, code, err = run("--fix-dry-run", filepath.Join("..", "testdata", "good.md"))
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if code != 0 {
+		t.Fatalf("expected exit 0 got %d output %s", code, out)
+	}
+
+	// Fixing in place and printing the fixes cannot be combined.
+	out, code, err = run("--fix", "--fix-dry-run", path)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if code == 0 || !strings.Contains(out, "if any flags in the group [fix fix-dry-run] are set none of the others can be") {
+		t.Fatalf("expected the flags to be rejected, got code %d output %s", code, out)
+	}
+	if got, _ := os.ReadFile(path); string(got) != src {
+		t.Fatalf("rejected run modified the file:\n%s", got)
+	}
+}
+
+// TestCLI_UnusedDirectives ensures dead suppression comments are reported
+// when enabled in the configuration.
+func TestCLI_UnusedDirectives(t *testing.T) {
+	dir := t.TempDir()
+	doc := filepath.Join(dir, "doc.md")
+	src := "# Title\n\n<!-- mdlint-disable-next-line MD1800 -->\nTrailing \n\n<!-- mdlint-disable MD1500 -->\nClean text.\n"
+	if err := os.WriteFile(doc, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out, code, err := run("--format", "text", doc)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if code != 0 || out != "" {
+		t.Fatalf("expected no findings got %d output %s", code, out)
+	}
+
+	cfg := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(cfg, []byte("version: 1\nsuppressions:\n  report_unused: true\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out, code, err = run("--config", cfg, "--format", "text", doc)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if code != 1 || !strings.Contains(out, ":6:1-6:31 MD0001 mdlint-disable directive suppresses no findings") ||
+		strings.Contains(out, "MD1800") {
+		t.Fatalf("unexpected: code %d output %s", code, out)
+	}
+}
+
+// TestCLI_FrontMatterConfig ensures a document may tune its own linting
+// through the mdlint front-matter key.
+func TestCLI_FrontMatterConfig(t *testing.T) {
+	dir := t.TempDir()
+	doc := filepath.Join(dir, "doc.md")
+	src := "---\ntitle: Guide\nmdlint:\n  ignored: [MD1800]\n  heading:\n    style: setext\n---\n# Title\n\nTrailing \n"
+	if err := os.WriteFile(doc, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out, code, err := run("--format", "text", doc)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if code != 1 || !strings.Contains(out, "MD1101 heading style should be setext, found atx") ||
+		strings.Contains(out, "MD1800") {
+		t.Fatalf("unexpected: code %d output %s", code, out)
+	}
+}
+
+// TestCLI_FrontMatterError ensures invalid front matter settings are reported
+// with the document's position while the other files are still linted.
+func TestCLI_FrontMatterError(t *testing.T) {
+	dir := t.TempDir()
+	bad := filepath.Join(dir, "bad.md")
+	if err := os.WriteFile(bad, []byte("---\nmdlint:\n  ignored: [MD100]\n---\n# Title\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "other.md"), []byte("# Other\n\nTrailing \n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out, code, err := run("--format", "text", dir)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	// go run reports the exit status of the command as its own output.
+	want := bad + `:3:3: front matter mdlint: ignored: unknown rule "MD100" (did you mean "MD1000"?)` + "\nexit status 2\n"
+	if code == 0 || !strings.Contains(out, "\n"+want) || !strings.Contains(out, "other.md:3:9-3:10 MD1800") {
+		t.Fatalf("unexpected: code %d output %s", code, out)
+	}
+}
+
+// TestCLI_PathOverrides ensures paths entries of the configuration apply to
+// files in nested directories.
+func TestCLI_PathOverrides(t *testing.T) {
+	dir := t.TempDir()
+	nested := filepath.Join(dir, "docs", "guide")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{filepath.Join(dir, "top.md"), filepath.Join(nested, "doc.md")} {
+		if err := os.WriteFile(p, []byte("# Title\n\nTrailing \n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := filepath.Join(dir, ".mdlintrc.yaml")
+	if err := os.WriteFile(cfg, []byte("version: 1\npaths:\n  \"docs/**\":\n    ignored: [MD1800]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out, code, err := run("--config", cfg, "--format", "text", dir)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if code != 1 || !strings.Contains(out, "top.md:3:9") || strings.Contains(out, "doc.md") {
+		t.Fatalf("unexpected: code %d output %s", code, out)
+	}
+}
+
+// TestCLI_ConfigPrint ensures the effective configuration of a file is printed
+// with the layer each value came from.
+func TestCLI_ConfigPrint(t *testing.T) {
+	dir := t.TempDir()
+	cfg := filepath.Join(dir, ".mdlintrc.yaml")
+	if err := os.WriteFile(cfg, []byte("version: 1\nignored: [MD1500]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	doc := filepath.Join(dir, "doc.md")
+	if err := os.WriteFile(doc, []byte("---\nmdlint:\n  ignored: [MD1800]\n---\n# Title\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out, code, err := run("config", "print", "--config", cfg, doc)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if code != 0 || !strings.Contains(out, "- MD1500 # "+cfg) || !strings.Contains(out, "- MD1800 # front matter") {
+		t.Fatalf("unexpected: code %d output %s", code, out)
+	}
+}
+
+// TestCLI_NestedConfig ensures configuration files in subdirectories extend
+// those of their parents.
+func TestCLI_NestedConfig(t *testing.T) {
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "sub")
+	if err := os.MkdirAll(filepath.Join(dir, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		filepath.Join(dir, ".mdlintrc.yaml"): "version: 1\nignored: [MD1500]\n",
+		filepath.Join(sub, ".mdlintrc.yaml"): "version: 1\nignored: [MD1800]\n",
+		filepath.Join(dir, "top.md"):         "# Title\n\nTrailing \n",
+		filepath.Join(sub, "doc.md"):         "# Title\n\nTrailing \n",
+	}
+	for p, data := range files {
+		if err := os.WriteFile(p, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	out, code, err := run("--format", "text", dir)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if code != 1 || !strings.Contains(out, "top.md:3:9-3:10 MD1800") || strings.Contains(out, "doc.md") {
+		t.Fatalf("unexpected: code %d output %s", code, out)
+	}
+}
+
+// TestCLI_Presets ensures presets are listed and selectable with --preset.
+func TestCLI_Presets(t *testing.T) {
+	out, code, err := run("config", "presets")
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if code != 0 || !strings.Contains(out, "strict ") || !strings.Contains(out, "relaxed ") {
+		t.Fatalf("unexpected: code %d output %s", code, out)
+	}
+
+	dir := t.TempDir()
+	doc := filepath.Join(dir, "doc.md")
+	if err := os.WriteFile(doc, []byte("# Title\n\nTrailing \n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out, code, err = run("--preset", "relaxed", "--format", "json", doc)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if code != 0 || !strings.Contains(out, `"rule":"MD1800","severity":"suggestion"`) {
+		t.Fatalf("unexpected: code %d output %s", code, out)
+	}
+}
+
+// TestCLI_ConfigTOML ensures -c accepts TOML configuration files.
+func TestCLI_ConfigTOML(t *testing.T) {
+	dir := t.TempDir()
+	cfg := filepath.Join(dir, "mdlint.toml")
+	if err := os.WriteFile(cfg, []byte("version = 1\n[output]\nformat = \"text\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out, code, err := run("-c", cfg, filepath.Join("..", "testdata", "bad.md"))
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if code != 1 || strings.HasPrefix(strings.TrimSpace(out), "[") {
+		t.Fatalf("expected text findings got %d output %s", code, out)
+	}
+}
+
+// TestCLI_RuleOptions ensures rule options are read from the rules section
+// and unknown options are rejected.
+func TestCLI_RuleOptions(t *testing.T) {
+	dir := t.TempDir()
+	doc := filepath.Join(dir, "doc.md")
+	if err := os.WriteFile(doc, []byte("# Title\n\nA line longer than twenty characters.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(cfg, []byte("version: 1\nrules:\n  MD1000:\n    line_length: 20\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out, code, err := run("--config", cfg, "--format", "text", doc)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if code != 1 || !strings.Contains(out, "MD1000") {
+		t.Fatalf("unexpected: code %d output %s", code, out)
+	}
+
+	if err := os.WriteFile(cfg, []byte("version: 1\nrules:\n  MD1000:\n    max: 20\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out, code, err = run("--config", cfg, doc)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	// go run reports the exit status of the command in its output.
+	if code == 0 || !strings.Contains(out, `rules.MD1000: unknown option "max"`) || !strings.Contains(out, "exit status 2") {
+		t.Fatalf("unexpected: code %d output %s", code, out)
+	}
+}
+
+// TestCLI_ConfigSchema ensures config schema describes the registered rules'
+// options.
+func TestCLI_ConfigSchema(t *testing.T) {
+	out, code, err := run("config", "schema")
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	for _, want := range []string{`"$schema"`, `"MD1000"`, `"line_length"`, `"setext"`} {
+		if code != 0 || !strings.Contains(out, want) {
+			t.Fatalf("expected %s: code %d output %s", want, code, out)
+		}
+	}
+}
+
+// TestCLI_ConfigErrorPosition ensures invalid configuration is reported with
+// its file, line and column and a suggestion.
+func TestCLI_ConfigErrorPosition(t *testing.T) {
+	dir := t.TempDir()
+	cfg := filepath.Join(dir, "mdlint.yaml")
+	if err := os.WriteFile(cfg, []byte("version: 1\nfailure_threshold: warn\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out, _, err := run("-c", cfg, filepath.Join("..", "testdata", "good.md"))
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if !strings.Contains(out, cfg+`:2:1: invalid failure threshold: invalid severity "warn" (did you mean "warning"?)`) {
+		t.Fatalf("unexpected output: %s", out)
+	}
+}
+
+// TestCLI_ConfigMigrate ensures files without a version are linted with a
+// deprecation warning and upgraded by config migrate.
+func TestCLI_ConfigMigrate(t *testing.T) {
+	dir := t.TempDir()
+	cfg := filepath.Join(dir, "mdlint.yaml")
+	if err := os.WriteFile(cfg, []byte("# team settings\nignored: [MD1800]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out, code, err := run("-c", cfg, filepath.Join("..", "testdata", "good.md"))
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if code != 0 || !strings.Contains(out, "warning: "+cfg+":2:1: version is missing") {
+		t.Fatalf("unexpected: code %d output %s", code, out)
+	}
+
+	if out, code, err = run("config", "migrate", cfg); err != nil || code != 0 {
+		t.Fatalf("migrate: code %d err %v output %s", code, err, out)
+	}
+	data, err := os.ReadFile(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "# team settings\nversion: 1\nignored: [MD1800]\n" {
+		t.Fatalf("unexpected migrated file:\n%s", data)
+	}
+	if out, _, _ = run("config", "migrate", cfg); !strings.Contains(out, "already at version 1") {
+		t.Fatalf("unexpected output: %s", out)
+	}
+}
+
+// TestCLI_EnvOverrides ensures MDLINT_ variables override the configuration
+// file, are overridden by flags and appear as sources in config print.
+func TestCLI_EnvOverrides(t *testing.T) {
+	dir := t.TempDir()
+	cfg := filepath.Join(dir, ".mdlintrc.yaml")
+	if err := os.WriteFile(cfg, []byte("version: 1\noutput:\n  format: json\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("MDLINT_OUTPUT_FORMAT", "text")
+	t.Setenv("MDLINT_IGNORED", "MD1500,MD1000")
+
+	out, code, err := run("config", "print", "--config", cfg)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if code != 0 || !strings.Contains(out, "format: text # environment MDLINT_OUTPUT_FORMAT") ||
+		!strings.Contains(out, "- MD1000 # environment MDLINT_IGNORED") {
+		t.Fatalf("unexpected: code %d output %s", code, out)
+	}
+
+	bad := filepath.Join("..", "testdata", "bad.md")
+	if out, _, _ = run("-c", cfg, bad); strings.HasPrefix(strings.TrimSpace(out), "[") {
+		t.Fatalf("expected text output, got %s", out)
+	}
+	if out, _, _ = run("-c", cfg, "--format", "json", bad); !strings.HasPrefix(strings.TrimSpace(out), "[") {
+		t.Fatalf("expected the flag to win, got %s", out)
+	}
+}
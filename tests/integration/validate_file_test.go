@@ -6,7 +6,7 @@ import (
 	"path/filepath"
 	"testing"
 
-	"mdlint"
+	"github.com/asymmetric-effort/mdlint"
 )
 
 // TestValidateMarkdownFromFile reads the project's README and validates it.
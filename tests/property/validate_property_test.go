@@ -6,7 +6,7 @@ import (
 	"testing"
 	"testing/quick"
 
-	"mdlint"
+	"github.com/asymmetric-effort/mdlint"
 )
 
 // TestValidateMarkdownProperties uses property-based testing to ensure
@@ -4,7 +4,7 @@ package mdlint_test
 import (
 	"testing"
 
-	"mdlint"
+	"github.com/asymmetric-effort/mdlint"
 )
 
 // TestValidateMarkdown ensures ValidateMarkdown returns nil for valid content